@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	aw "github.com/deanishe/awgo"
@@ -65,17 +66,33 @@ func run() {
 			Icon(randomOwnerIcon()).
 			Autocomplete(":").
 			Subtitle(fmt.Sprintf("%v Owners", libsummary.owners))
+
+		saved := loadSavedSearches()
+		for _, name := range savedSearchNames(saved) {
+			wf.NewItem("@" + name).
+				Icon(savedIcon).
+				Autocomplete(fmt.Sprintf("@%s ", name)).
+				Subtitle(strings.Join(saved[name], " "))
+		}
 		wf.NewItem("sync")
 		wf.NewItem("set-editor")
 		wf.NewItem("login")
 	} else {
 		switch {
 		case strings.HasPrefix(alfredQuery, "#"):
-			fieldSummary("Tags")
+			fieldSummary("Tags", &squery)
 		case strings.HasPrefix(alfredQuery, "~"):
-			fieldSummary("Language")
+			fieldSummary("Language", &squery)
 		case strings.HasPrefix(alfredQuery, ":"):
-			fieldSummary("Owner")
+			fieldSummary("Owner", &squery)
+		case strings.HasPrefix(alfredQuery, "@"):
+			savedSearchAlfred(alfredQuery)
+		case strings.HasPrefix(alfredQuery, "related:"):
+			// Gists related to a gist; set by the ctrl action
+			if v, err := strconv.Atoi(strings.TrimPrefix(alfredQuery, "related:")); err == nil {
+				squery.limit = 100
+				related(v, &squery)
+			}
 		default:
 			queryGistsAlfred(alfredQuery)
 		}
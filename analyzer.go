@@ -0,0 +1,152 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis"
+	"github.com/blevesearch/bleve/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/analysis/token/lowercase"
+	regexpTokenizer "github.com/blevesearch/bleve/analysis/tokenizer/regexp"
+	"github.com/blevesearch/bleve/analysis/tokenizer/single"
+	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/registry"
+)
+
+// Analyzer for file contents and symbols
+const codeAnalyzer = "code"
+
+// Analyzer for tags; each tag is a single term
+const tagAnalyzer = "tag"
+
+// The index is opened while package variables are initialized,
+// so the filter is registered by a variable rather than init.
+var codePartsFilter = registerCodeParts("code_parts")
+
+// Words, identifiers, flags (without leading dashes), and paths
+var codeToken = `[\p{L}\p{N}_]+(?:[-./:@]+[\p{L}\p{N}_]+)*`
+
+// codeParts - indexes the parts of identifiers, flags, and paths
+// (getUserName, read_csv, --no-verify, /usr/bin) along with the
+// whole token. Parts share the position of the whole token.
+// Underscores are not letters, so snake_case splits on them.
+type codeParts struct{}
+
+func (f *codeParts) Filter(input analysis.TokenStream) analysis.TokenStream {
+	var output analysis.TokenStream
+	for _, token := range input {
+		output = append(output, token)
+		seen := map[string]bool{string(token.Term): true}
+		for _, part := range splitCode(token.Term) {
+			term := token.Term[part[0]:part[1]]
+			if seen[string(term)] {
+				continue
+			}
+			seen[string(term)] = true
+			output = append(output, &analysis.Token{
+				Term:     term,
+				Start:    token.Start + part[0],
+				End:      token.Start + part[1],
+				Position: token.Position,
+				Type:     token.Type,
+			})
+		}
+	}
+	return output
+}
+
+// splitCode - returns the [start, end) byte offsets of the parts of
+// term split on punctuation (pysam.AlignmentFile -> pysam AlignmentFile)
+// and of those parts split on case changes (Alignment File).
+func splitCode(term []byte) [][2]int {
+	var parts [][2]int
+	start := -1
+	for i := 0; i <= len(term); {
+		r, size := utf8.RuneError, 1
+		if i < len(term) {
+			r, size = utf8.DecodeRune(term[i:])
+		}
+		isSeparator := i == len(term) || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+		if isSeparator && start >= 0 {
+			parts = append(parts, [2]int{start, i})
+			parts = append(parts, splitCase(term, start, i)...)
+			start = -1
+		} else if !isSeparator && start < 0 {
+			start = i
+		}
+		i += size
+	}
+	return parts
+}
+
+// splitCase - splits term[start:end] on case changes;
+// getUser -> get User, HTTPServer -> HTTP Server
+func splitCase(term []byte, start int, end int) [][2]int {
+	var parts [][2]int
+	var prev rune
+	partStart := start
+	for i := start; i < end; {
+		r, size := utf8.DecodeRune(term[i:end])
+		var next rune
+		if i+size < end {
+			next, _ = utf8.DecodeRune(term[i+size : end])
+		}
+		if i > start && unicode.IsUpper(r) && (unicode.IsLower(prev) || (unicode.IsUpper(prev) && unicode.IsLower(next))) {
+			parts = append(parts, [2]int{partStart, i})
+			partStart = i
+		}
+		prev = r
+		i += size
+	}
+	if partStart == start {
+		return nil
+	}
+	return append(parts, [2]int{partStart, end})
+}
+
+func registerCodeParts(name string) string {
+	registry.RegisterTokenFilter(name, func(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+		return &codeParts{}, nil
+	})
+	return name
+}
+
+// indexMapping - contents of files and symbols use the code
+// analyzer and tags and collections are kept whole. Other fields use the
+// standard analyzer.
+func indexMapping() mapping.IndexMapping {
+	indexMapping := bleve.NewIndexMapping()
+	err := indexMapping.AddCustomTokenizer(codeAnalyzer, map[string]interface{}{
+		"type":   regexpTokenizer.Name,
+		"regexp": codeToken,
+	})
+	check(err)
+	err = indexMapping.AddCustomAnalyzer(codeAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     codeAnalyzer,
+		"token_filters": []string{codePartsFilter, lowercase.Name},
+	})
+	check(err)
+	err = indexMapping.AddCustomAnalyzer(tagAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+	check(err)
+
+	files := bleve.NewDocumentMapping()
+	files.DefaultAnalyzer = codeAnalyzer
+	indexMapping.DefaultMapping.AddSubDocumentMapping("Files", files)
+
+	symbols := bleve.NewTextFieldMapping()
+	symbols.Analyzer = codeAnalyzer
+	indexMapping.DefaultMapping.AddFieldMappingsAt("Symbols", symbols)
+
+	for _, field := range []string{"Tags", "RuleTags", "TagPaths", "Collections"} {
+		tags := bleve.NewTextFieldMapping()
+		tags.Analyzer = tagAnalyzer
+		indexMapping.DefaultMapping.AddFieldMappingsAt(field, tags)
+	}
+	return indexMapping
+}
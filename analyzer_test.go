@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodeAnalyzer(t *testing.T) {
+	tests := []struct {
+		text      string
+		want      []string
+		positions []int
+	}{
+		{"getUserName", []string{"getusername", "get", "user", "name"}, []int{1, 1, 1, 1}},
+		{"read_csv", []string{"read_csv", "read", "csv"}, []int{1, 1, 1}},
+		{"git commit --no-verify", []string{"git", "commit", "no-verify", "no", "verify"}, []int{1, 2, 3, 3, 3}},
+		{"/usr/bin/env", []string{"usr/bin/env", "usr", "bin", "env"}, []int{1, 1, 1, 1}},
+		{"pysam.AlignmentFile", []string{"pysam.alignmentfile", "pysam", "alignmentfile", "alignment", "file"}, []int{1, 1, 1, 1, 1}},
+		{"HTTPServer", []string{"httpserver", "http", "server"}, []int{1, 1, 1}},
+		{"Größe_Datei", []string{"größe_datei", "größe", "datei"}, []int{1, 1, 1}},
+	}
+	analyzer := indexMapping().AnalyzerNamed(codeAnalyzer)
+	for _, tt := range tests {
+		var terms []string
+		var positions []int
+		for _, token := range analyzer.Analyze([]byte(tt.text)) {
+			terms = append(terms, string(token.Term))
+			positions = append(positions, token.Position)
+		}
+		if !reflect.DeepEqual(terms, tt.want) || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("Analyze(%q) = %q %v; want %q %v", tt.text, terms, positions, tt.want, tt.positions)
+		}
+	}
+}
+
+func TestSplitCase(t *testing.T) {
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"getUser", []string{"get", "User"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"parseHTTP", []string{"parse", "HTTP"}},
+		{"lower", nil},
+		{"UPPER", nil},
+	}
+	for _, tt := range tests {
+		var parts []string
+		for _, part := range splitCase([]byte(tt.term), 0, len(tt.term)) {
+			parts = append(parts, tt.term[part[0]:part[1]])
+		}
+		if !reflect.DeepEqual(parts, tt.want) {
+			t.Errorf("splitCase(%q) = %q; want %q", tt.term, parts, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/blevesearch/bleve/search"
+	"github.com/gdamore/tcell"
+	"github.com/pkg/browser"
+	"github.com/rivo/tview"
+)
+
+var browseHelp = "[::b]enter[::-] open  [::b]c[::-] copy  [::b]e[::-] edit  [::b]s[::-] star  [::b]w[::-] web  [::b]d[::-] delete  [::b]tab[::-] search  [::b]q[::-] quit"
+
+// browse - full-screen interactive browser
+// The search box drives the same query as ls.
+func browse(browseQuery *searchQuery) {
+	if outputPipe() {
+		ThrowError("gg browse requires a terminal", 1)
+	}
+
+	var hits []*search.DocumentMatch
+	var openIdx = -1
+	columns := resultColumns()
+
+	app := tview.NewApplication()
+	input := tview.NewInputField().
+		SetLabel("Search: ").
+		SetText(browseQuery.term)
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	preview := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(false)
+	preview.SetBorder(true)
+	status := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(browseHelp)
+	modal := tview.NewModal().
+		AddButtons([]string{"Delete", "Cancel"})
+
+	setStatus := func(msg string) {
+		status.SetText(fmt.Sprintf("%s  %s", msg, browseHelp))
+	}
+
+	selected := func() *search.DocumentMatch {
+		row, _ := table.GetSelection()
+		if row < 1 || row > len(hits) {
+			return nil
+		}
+		return hits[row-1]
+	}
+
+	showPreview := func() {
+		preview.Clear()
+		preview.SetTitle("")
+		gist := selected()
+		if gist == nil {
+			return
+		}
+		preview.SetTitle(fmt.Sprintf(" %v ", tview.Escape(gist.Fields["Description"].(string))))
+		fileset := parseGistFiles(gist)
+		filenames := make([]string, 0, len(fileset))
+		for filename := range fileset {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+		w := tview.ANSIWriter(preview)
+		for _, filename := range filenames {
+			file := fileset[filename]
+			fmt.Fprintf(w, "%s %s\n", greenText.Sprint(file["filename"]), file["language"])
+			highlight(w, file["filename"], file["content"], "terminal256", chromaStyle())
+			fmt.Fprint(w, "\n\n")
+		}
+		preview.ScrollToBeginning()
+	}
+
+	// Rerun the query and select row (1 is the first result)
+	refresh := func(row int) {
+		browseQuery.term = strings.Trim(input.GetText(), " ")
+		results, isQuery, isFuzzy, err := runQuery(browseQuery)
+		hits = nil
+		if err == nil {
+			hits = results.Hits
+		}
+
+		table.Clear()
+		header := resultHeader(columns, isQuery)
+		descIdx := indexOf(header, "Description")
+		for col, field := range header {
+			table.SetCell(0, col, tview.NewTableCell(field).
+				SetAttributes(tcell.AttrBold).
+				SetSelectable(false))
+		}
+		for idx, gist := range hits {
+			for col, field := range resultRow(gist, idx+1, columns, isQuery) {
+				table.SetCell(idx+1, col, tview.NewTableCell(tview.TranslateANSI(tview.Escape(field))).
+					SetExpansion(ifInt(col == descIdx, 1, 0)))
+			}
+		}
+
+		switch {
+		case len(hits) == 0:
+			setStatus("[red::b]No Results[-::-]")
+		case isFuzzy:
+			setStatus(fmt.Sprintf("[::b]%s[::-]", fuzzyNotice(browseQuery)))
+		default:
+			setStatus(fmt.Sprintf("[blue::b]%v of %v[-::-]", len(hits), results.Total))
+		}
+
+		if row > len(hits) {
+			row = len(hits)
+		}
+		table.Select(ifInt(row < 1, 1, row), 0)
+		showPreview()
+	}
+
+	pages := tview.NewPages()
+
+	input.SetChangedFunc(func(text string) {
+		refresh(1)
+	})
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			app.Stop()
+		default:
+			app.SetFocus(table)
+		}
+	})
+
+	table.SetSelectionChangedFunc(func(row, column int) {
+		showPreview()
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyBacktab:
+			app.SetFocus(input)
+			return nil
+		case tcell.KeyEscape:
+			app.Stop()
+			return nil
+		}
+		if event.Rune() == 'q' {
+			app.Stop()
+			return nil
+		} else if event.Rune() == '/' {
+			app.SetFocus(input)
+			return nil
+		}
+
+		gist := selected()
+		if gist == nil {
+			return event
+		}
+		row, _ := table.GetSelection()
+		gistIdx := int(gist.Fields["IDX"].(float64))
+		switch {
+		case event.Key() == tcell.KeyEnter, event.Rune() == 'o':
+			openIdx = gistIdx
+			app.Stop()
+		case event.Rune() == 'c':
+			if err := clipboard.WriteAll(gistContent(gist)); err != nil {
+				setStatus(fmt.Sprintf("[red::b]Error copying: %s[-::-]", tview.Escape(err.Error())))
+				break
+			}
+			recordUsage(gist, "copy")
+			setStatus("[green::b]Copied to clipboard[-::-]")
+		case event.Rune() == 'e':
+			app.Suspend(func() {
+				editGist(gistIdx)
+			})
+			refresh(row)
+		case event.Rune() == 's':
+			starred := gist.Fields["Starred"].(string) == "T"
+			// Errors exit, so the terminal is restored first
+			app.Suspend(func() {
+				starGist(gistIdx, !starred)
+			})
+			refresh(row)
+			setStatus(fmt.Sprintf("[green::b]%s %v[-::-]", ifelse(starred, "Unstarred", "Starred"), gistIdx))
+		case event.Rune() == 'w':
+			browser.OpenURL(gist.Fields["URL"].(string))
+		case event.Rune() == 'd':
+			modal.SetText(fmt.Sprintf("Delete %v: %s?", gistIdx, gist.Fields["Description"].(string))).
+				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+					pages.HidePage("confirm")
+					if buttonLabel == "Delete" {
+						app.Suspend(func() {
+							rmGist(gistIdx)
+						})
+						refresh(row)
+						setStatus(fmt.Sprintf("[green::b]Removed %v[-::-]", gistIdx))
+					}
+					app.SetFocus(table)
+				})
+			pages.ShowPage("confirm")
+			app.SetFocus(modal)
+		default:
+			return event
+		}
+		return nil
+	})
+
+	layout := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, false).
+		AddItem(table, 0, 1, true).
+		AddItem(preview, 0, 1, false).
+		AddItem(status, 1, 0, false)
+	pages.AddPage("main", layout, true, true).
+		AddPage("confirm", modal, true, false)
+
+	refresh(1)
+	if err := app.SetRoot(pages, true).SetFocus(input).Run(); err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+
+	if openIdx >= 0 {
+		outputGist(openIdx)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/search"
+)
+
+// Collections and pinned gists are local and keyed by GistID
+// so that they persist across syncs and rebuilds.
+var libCollectionsPath = fmt.Sprintf("%s/collections.json", getLibraryDirectory())
+
+type localCollections struct {
+	Collections map[string][]string `json:"collections"`
+	Pinned      []string            `json:"pinned"`
+}
+
+func loadCollections() localCollections {
+	collections := localCollections{Collections: map[string][]string{}}
+	data, err := ioutil.ReadFile(libCollectionsPath)
+	if err != nil {
+		return collections
+	}
+	if err := json.Unmarshal(data, &collections); err != nil {
+		ThrowError(fmt.Sprintf("Error reading %s", libCollectionsPath), 1)
+	}
+	if collections.Collections == nil {
+		collections.Collections = map[string][]string{}
+	}
+	return collections
+}
+
+func writeCollections(collections localCollections) {
+	_ = os.Mkdir(getLibraryDirectory(), 0755)
+	out, err := json.MarshalIndent(collections, "", "  ")
+	check(err)
+	err = ioutil.WriteFile(libCollectionsPath, out, 0644)
+	check(err)
+}
+
+// collectionName - returns the name of an existing collection
+// regardless of case
+func (c localCollections) collectionName(name string) (string, bool) {
+	for existing := range c.Collections {
+		if strings.EqualFold(existing, name) {
+			return existing, true
+		}
+	}
+	return "", false
+}
+
+// gistCollections - names of the collections containing gistID
+func (c localCollections) gistCollections(gistID string) []string {
+	names := []string{}
+	for name, gistIDs := range c.Collections {
+		if contains(gistIDs, gistID) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c localCollections) isPinned(gistID string) bool {
+	return contains(c.Pinned, gistID)
+}
+
+// applyCollections - sets collections and pinning from local storage
+func applyCollections(sn Snippet, collections localCollections) Snippet {
+	sn.Collections = collections.gistCollections(sn.GistID)
+	sn.Pinned = trueFalse(collections.isPinned(sn.GistID))
+	return sn
+}
+
+// reindexCollections - updates the index records of gists
+// after their collections or pinning change
+func reindexCollections(gists []*search.DocumentMatch) {
+	collections := loadCollections()
+	batch := dbIdx.NewBatch()
+	for _, gist := range gists {
+		sn := applyCollections(snippetFromDoc(gist), collections)
+		batch.Index(sn.ID, sn)
+	}
+	if err := dbIdx.Batch(batch); err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+}
+
+// gistIDs - returns the GistIDs of gists
+func gistIDs(gists []*search.DocumentMatch) []string {
+	ids := make([]string, len(gists))
+	for i, gist := range gists {
+		ids[i] = fmt.Sprintf("%v", gist.Fields["GistID"])
+	}
+	return ids
+}
+
+func removeAll(list []string, remove []string) []string {
+	var result []string
+	for _, item := range list {
+		if contains(remove, item) == false {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func createCollection(name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		ThrowError("Provide a collection name", 1)
+	}
+	collections := loadCollections()
+	if existing, ok := collections.collectionName(name); ok {
+		ThrowError(fmt.Sprintf("Collection '%s' already exists", existing), 1)
+	}
+	collections.Collections[name] = []string{}
+	writeCollections(collections)
+	successMsg(fmt.Sprintf("Created collection '%s'\n", name))
+}
+
+func existingCollection(collections localCollections, name string) string {
+	existing, ok := collections.collectionName(name)
+	if !ok {
+		ThrowError(fmt.Sprintf("No collection '%s'; Create it with 'gg collection create %s'", name, name), 1)
+	}
+	return existing
+}
+
+func addToCollection(name string, ids []string) {
+	if len(ids) == 0 {
+		ThrowError("Provide a collection name and one or more gist IDs", 1)
+	}
+	collections := loadCollections()
+	name = existingCollection(collections, name)
+	gists := idxGists(ids)
+	for _, gistID := range gistIDs(gists) {
+		if contains(collections.Collections[name], gistID) == false {
+			collections.Collections[name] = append(collections.Collections[name], gistID)
+		}
+	}
+	writeCollections(collections)
+	reindexCollections(gists)
+	successMsg(fmt.Sprintf("Added %v gist%s to '%s'\n", len(gists), ifelse(len(gists) == 1, "", "s"), name))
+}
+
+// rmFromCollection - removes gists from a collection, or
+// the collection itself when no IDs are given
+func rmFromCollection(name string, ids []string) {
+	collections := loadCollections()
+	name = existingCollection(collections, name)
+	if len(ids) == 0 {
+		count := len(collections.Collections[name])
+		if count > 0 && confirmPrompt(fmt.Sprintf("Remove collection '%s' of %v gists?", name, count)) == false {
+			return
+		}
+		delete(collections.Collections, name)
+		writeCollections(collections)
+		reindexCollections(termGists("Collections", strings.ToLower(name)))
+		successMsg(fmt.Sprintf("Removed collection '%s'\n", name))
+		return
+	}
+	gists := idxGists(ids)
+	collections.Collections[name] = removeAll(collections.Collections[name], gistIDs(gists))
+	writeCollections(collections)
+	reindexCollections(gists)
+	successMsg(fmt.Sprintf("Removed %v gist%s from '%s'\n", len(gists), ifelse(len(gists) == 1, "", "s"), name))
+}
+
+func collectionTable() {
+	collections := loadCollections()
+	if len(collections.Collections) == 0 {
+		errorMsg("No collections; Create one with 'gg collection create <name>'\n")
+		return
+	}
+	names := make([]string, 0, len(collections.Collections))
+	for name := range collections.Collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	data := make([][]string, len(names))
+	for i, name := range names {
+		data[i] = []string{name, fmt.Sprintf("%v", len(collections.Collections[name]))}
+	}
+
+	renderTable([]string{"Collection", "Gists"}, data)
+}
+
+// pinGists - pins or unpins gists
+func pinGists(ids []string, pin bool) {
+	if len(ids) == 0 {
+		ThrowError("Provide one or more gist IDs", 1)
+	}
+	collections := loadCollections()
+	gists := idxGists(ids)
+	if pin {
+		for _, gistID := range gistIDs(gists) {
+			if collections.isPinned(gistID) == false {
+				collections.Pinned = append(collections.Pinned, gistID)
+			}
+		}
+	} else {
+		collections.Pinned = removeAll(collections.Pinned, gistIDs(gists))
+	}
+	writeCollections(collections)
+	reindexCollections(gists)
+	successMsg(fmt.Sprintf("%s %s\n", ifelse(pin, "Pinned", "Unpinned"), strings.Join(ids, ", ")))
+}
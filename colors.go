@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/chroma/styles"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Used when neither --style nor the config sets a style
+const defaultStyle = "fruity"
+
+// Chroma style and formatter set by --style and --formatter
+var highlightStyle string
+var highlightFormatter string
+
+// Formatters for terminals; noop disables highlighting
+var terminalFormatters = []string{"terminal", "terminal256", "terminal16m", "noop"}
+
+// initColor - disables color when NO_COLOR is set (https://no-color.org)
+func initColor() {
+	if os.Getenv("NO_COLOR") != "" {
+		color.NoColor = true
+	}
+}
+
+func validStyle(style string) {
+	if _, ok := styles.Registry[style]; !ok {
+		ThrowError(fmt.Sprintf("Unknown style '%s'; Run 'gg themes' to see styles", style), 1)
+	}
+}
+
+func validFormatter(formatter string) {
+	if contains(terminalFormatters, formatter) == false {
+		ThrowError(fmt.Sprintf("Unknown formatter '%s'; Use one of: %s", formatter, strings.Join(terminalFormatters, ", ")), 1)
+	}
+}
+
+// setHighlight - applies --style and --formatter
+func setHighlight(style string, formatter string) {
+	if style = strings.ToLower(style); style != "" {
+		validStyle(style)
+		highlightStyle = style
+	}
+	if formatter = strings.ToLower(formatter); formatter != "" {
+		validFormatter(formatter)
+		highlightFormatter = formatter
+	}
+}
+
+// chromaStyle - style from --style, the config, or the default
+func chromaStyle() string {
+	if highlightStyle != "" {
+		return highlightStyle
+	}
+	if config, err := getConfig(); err == nil && config.Style != "" {
+		validStyle(config.Style)
+		return config.Style
+	}
+	return defaultStyle
+}
+
+// chromaFormatter - formatter from --formatter, the config, or
+// the colors supported by the terminal
+func chromaFormatter() string {
+	if color.NoColor {
+		return "noop"
+	}
+	if highlightFormatter != "" {
+		return highlightFormatter
+	}
+	if config, err := getConfig(); err == nil && config.Formatter != "" {
+		validFormatter(config.Formatter)
+		return config.Formatter
+	}
+	return detectFormatter()
+}
+
+// detectFormatter - picks a formatter using COLORTERM and TERM
+func detectFormatter() string {
+	return formatterFor(os.Getenv("TERM"), os.Getenv("COLORTERM"), terminal.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// formatterFor - colors are left out when output is not
+// a terminal or the terminal cannot show them
+func formatterFor(term string, colorterm string, isTerminal bool) string {
+	term = strings.ToLower(term)
+	colorterm = strings.ToLower(colorterm)
+	switch {
+	case isTerminal == false || term == "" || term == "dumb":
+		return "noop"
+	case colorterm == "truecolor" || colorterm == "24bit":
+		return "terminal16m"
+	case strings.Contains(term, "256color"):
+		return "terminal256"
+	default:
+		return "terminal"
+	}
+}
+
+// setHeaderColors - bolds the header of a table unless color is disabled
+func setHeaderColors(table *tablewriter.Table, n int) {
+	if color.NoColor {
+		return
+	}
+	headerColors := make([]tablewriter.Colors, n)
+	for i := 0; i < n; i++ {
+		headerColors[i] = tablewriter.Colors{tablewriter.Bold}
+	}
+	table.SetHeaderColor(headerColors...)
+}
+
+// Code used to preview styles
+var themeSample = `# Count reads in a BAM file
+import pysam
+
+def count_reads(path, min_quality=20):
+    """Return the number of reads above min_quality"""
+    with pysam.AlignmentFile(path) as bam:
+        return sum(1 for read in bam if read.mapping_quality >= min_quality)
+`
+
+// themes - previews styles with a sample or the first file of a gist
+func themes(gistIdx string) {
+	filename, content := "sample.py", themeSample
+	if gistIdx != "" {
+		gist := idxGists([]string{gistIdx})[0]
+		fileset := parseGistFiles(gist)
+		filename = ""
+		for name := range fileset {
+			if filename == "" || name < filename {
+				filename = name
+			}
+		}
+		content = fileset[filename]["content"]
+	}
+	var out bytes.Buffer
+	for _, name := range styles.Names() {
+		if color.NoColor {
+			fmt.Fprintln(&out, name)
+			continue
+		}
+		fmt.Fprintln(&out, boldUnderline.Sprint(name)+ifelse(name == chromaStyle(), " (current)", ""))
+		highlight(&out, filename, content, chromaFormatter(), name)
+		out.WriteString("\n\n")
+	}
+	page(out.String())
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestFormatterFor(t *testing.T) {
+	tests := []struct {
+		term       string
+		colorterm  string
+		isTerminal bool
+		want       string
+	}{
+		{"xterm-256color", "truecolor", true, "terminal16m"},
+		{"xterm-256color", "24bit", true, "terminal16m"},
+		{"xterm-256color", "", true, "terminal256"},
+		{"xterm", "", true, "terminal"},
+		{"dumb", "", true, "noop"},
+		{"", "truecolor", true, "noop"},
+		{"xterm-256color", "truecolor", false, "noop"},
+	}
+	for _, tt := range tests {
+		if got := formatterFor(tt.term, tt.colorterm, tt.isTerminal); got != tt.want {
+			t.Errorf("formatterFor(%q, %q, %v) = %q; want %q", tt.term, tt.colorterm, tt.isTerminal, got, tt.want)
+		}
+	}
+}
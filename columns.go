@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/search"
+	"github.com/olekukonko/tablewriter"
+)
+
+// tableColumn - a column of the result table
+// The value of a column is given the position of the row,
+// which @N refers to
+type tableColumn struct {
+	name   string
+	header string
+	value  func(gist *search.DocumentMatch, pos int) string
+}
+
+// Columns available to --columns
+var tableColumns = []tableColumn{
+	{"pos", "#", func(gist *search.DocumentMatch, pos int) string {
+		return fmt.Sprintf("%v", pos)
+	}},
+	{"id", "ID", func(gist *search.DocumentMatch, pos int) string {
+		return fmt.Sprintf("%v", gist.Fields["IDX"])
+	}},
+	{"star", "⭐", func(gist *search.DocumentMatch, pos int) string {
+		return ifelse(fieldString(gist, "Starred") == "T", "⭐", "")
+	}},
+	{"lock", "🔒", func(gist *search.DocumentMatch, pos int) string {
+		return ifelse(fieldString(gist, "Public") == "F", "🔒", "")
+	}},
+	{"desc", "Description", func(gist *search.DocumentMatch, pos int) string {
+		var pinned string
+		if fieldString(gist, "Pinned") == "T" {
+			pinned = "📌 "
+		}
+		// Tags derived by rules follow the description
+		var ruleTags string
+		if tags := ruleTagString(gist); tags != "" {
+			ruleTags = " " + ruleTagText.Sprint(tags)
+		}
+		return pinned + highlightLocations(fmt.Sprintf("%.60v", fieldString(gist, "Description")), fieldLocations(gist, "Description", -1)) + ruleTags
+	}},
+	{"files", "Filename", func(gist *search.DocumentMatch, pos int) string {
+		return highlightField(gist, "Filename")
+	}},
+	{"lang", "Language", func(gist *search.DocumentMatch, pos int) string {
+		return highlightField(gist, "Language")
+	}},
+	{"owner", "Owner", func(gist *search.DocumentMatch, pos int) string {
+		return highlightField(gist, "Owner")
+	}},
+	{"tags", "Tags", func(gist *search.DocumentMatch, pos int) string {
+		var tags []string
+		for _, tag := range fieldStrings(gist, "Tags") {
+			tags = append(tags, "#"+tag)
+		}
+		if ruleTags := ruleTagString(gist); ruleTags != "" {
+			tags = append(tags, ruleTagText.Sprint(ruleTags))
+		}
+		return strings.Join(tags, " ")
+	}},
+	{"lines", "n", func(gist *search.DocumentMatch, pos int) string {
+		return fmt.Sprintf("%v", fieldFloat(gist, "NLines"))
+	}},
+	{"size", "Size", func(gist *search.DocumentMatch, pos int) string {
+		return formatByteSize(fieldFloat(gist, "Size"))
+	}},
+	{"comments", "Comments", func(gist *search.DocumentMatch, pos int) string {
+		return fmt.Sprintf("%v", fieldFloat(gist, "Comments"))
+	}},
+	{"created", "Created", func(gist *search.DocumentMatch, pos int) string {
+		return strings.Split(fieldString(gist, "CreatedAt"), "T")[0]
+	}},
+	{"updated", "Updated", func(gist *search.DocumentMatch, pos int) string {
+		return strings.Split(fieldString(gist, "UpdatedAt"), "T")[0]
+	}},
+}
+
+// Used when neither --columns nor the config sets columns
+var defaultColumns = []string{"id", "star", "lock", "desc", "files", "lang", "owner", "lines", "updated"}
+
+// Columns are dropped in this order until the table fits the terminal
+var defaultDropColumns = []string{"updated", "owner", "lang"}
+
+// Columns set by --columns
+var listColumns []string
+
+func columnNames() []string {
+	names := make([]string, len(tableColumns))
+	for i, column := range tableColumns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// parseColumns - parses a list of column names
+func parseColumns(columns []string, source string) []string {
+	var names []string
+	for _, name := range columns {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if contains(columnNames(), name) == false {
+			ThrowError(fmt.Sprintf("Unknown column '%s' in %s; Use: %s", name, source, strings.Join(columnNames(), ", ")), 1)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// setTableColumns - applies --columns
+func setTableColumns(columns string) {
+	if columns == "" {
+		return
+	}
+	listColumns = parseColumns(strings.Split(columns, ","), "--columns")
+	if len(listColumns) == 0 {
+		ThrowError("Provide one or more columns with --columns", 1)
+	}
+}
+
+// resultColumns - columns of the result table from
+// --columns, the config, or the default
+func resultColumns() []tableColumn {
+	names := listColumns
+	if names == nil {
+		if config, err := getConfig(); err == nil && len(config.Columns) > 0 {
+			names = parseColumns(config.Columns, "the config")
+		} else {
+			names = defaultColumns
+		}
+	}
+	var columns []tableColumn
+	for _, name := range names {
+		for _, column := range tableColumns {
+			if column.name == name {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
+
+// dropColumnHeaders - headers of the columns to drop first
+// on narrow terminals
+func dropColumnHeaders() []string {
+	names := defaultDropColumns
+	if config, err := getConfig(); err == nil && config.DropColumns != nil {
+		names = parseColumns(config.DropColumns, "drop_columns")
+	}
+	var headers []string
+	for _, name := range names {
+		for _, column := range tableColumns {
+			if column.name == name {
+				headers = append(headers, column.header)
+			}
+		}
+	}
+	return headers
+}
+
+// tableWidth - estimates the width of a result table
+// with tab separated columns
+func tableWidth(header []string, tableData [][]string) int {
+	var width int
+	for idx, field := range header {
+		colWidth := tablewriter.DisplayWidth(field)
+		for _, row := range tableData {
+			if w := tablewriter.DisplayWidth(row[idx]); w > colWidth {
+				colWidth = w
+			}
+		}
+		// Padded cells are followed by a tab
+		width += colWidth + 2
+		if idx < len(header)-1 {
+			width = (width/8 + 1) * 8
+		}
+	}
+	return width
+}
+
+// fitColumns - drops columns in the configured order until
+// the table fits within xsize
+func fitColumns(header []string, tableData [][]string, xsize int) ([]string, [][]string) {
+	// The width is unknown when not in a terminal
+	if xsize <= 0 {
+		return header, tableData
+	}
+	for _, drop := range dropColumnHeaders() {
+		if tableWidth(header, tableData) <= xsize || len(header) == 1 {
+			break
+		}
+		if contains(header, drop) {
+			header, tableData = removeFields(header, tableData, []string{drop})
+		}
+	}
+	return header, tableData
+}
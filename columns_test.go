@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFitColumns(t *testing.T) {
+	if config, err := getConfig(); err == nil && config.DropColumns != nil {
+		t.Skip("drop_columns is set in the config")
+	}
+	header := []string{"ID", "Description", "Language", "Owner", "Updated"}
+	// removeFields replaces the rows of the table it is given
+	data := func() [][]string {
+		return [][]string{{"1", "Upload to s3", "Python", "octocat", "2 days ago"}}
+	}
+	without := func(drop ...string) []string {
+		h, _ := removeFields(header, data(), drop)
+		return h
+	}
+	width := func(drop ...string) int {
+		h, d := removeFields(header, data(), drop)
+		return tableWidth(h, d)
+	}
+	tests := []struct {
+		xsize int
+		want  []string
+	}{
+		{0, header},
+		{width(), header},
+		// Updated, Owner, then Language are dropped
+		{width() - 1, without("Updated")},
+		{width("Updated") - 1, without("Updated", "Owner")},
+		{width("Updated", "Owner") - 1, without("Updated", "Owner", "Language")},
+		// Other columns are kept even when the table is too wide
+		{1, without("Updated", "Owner", "Language")},
+	}
+	for _, tt := range tests {
+		got, gotData := fitColumns(header, data(), tt.xsize)
+		if !reflect.DeepEqual(got, tt.want) || len(gotData[0]) != len(tt.want) {
+			t.Errorf("fitColumns(%v) = %q; want %q", tt.xsize, got, tt.want)
+		}
+	}
+	// Columns missing from the table are skipped
+	h, d := removeFields(header, data(), []string{"Updated"})
+	if got, _ := fitColumns(h, d, tableWidth(h, d)-1); !reflect.DeepEqual(got, without("Updated", "Owner")) {
+		t.Errorf("fitColumns() without Updated = %q; want %q", got, without("Updated", "Owner"))
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/search/query"
+)
+
+// Absolute date formats accepted by date filters
+var dateFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// Relative dates; e.g. 12h, 7d, 3w, 6m, 1y
+var relativeDate = regexp.MustCompile(`^(\d+)([hdwmy])$`)
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(t time.Time) time.Time {
+	// Weeks start on Monday
+	return startOfDay(t).AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func startOfYear(t time.Time) time.Time {
+	return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+}
+
+// parseDate - parses an absolute or relative date into
+// the period [start, end) that it covers. Relative offsets
+// (7d) are a single point in time, while named periods
+// (last-month) and dates (2020-01) cover the whole period.
+func parseDate(s string, now time.Time) (time.Time, time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if m := relativeDate.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		var t time.Time
+		switch m[2] {
+		case "h":
+			t = now.Add(-time.Duration(n) * time.Hour)
+		case "d":
+			t = now.AddDate(0, 0, -n)
+		case "w":
+			t = now.AddDate(0, 0, -7*n)
+		case "m":
+			t = now.AddDate(0, -n, 0)
+		case "y":
+			t = now.AddDate(-n, 0, 0)
+		}
+		return t, t, nil
+	}
+
+	switch s {
+	case "now":
+		return now, now, nil
+	case "today":
+		return startOfDay(now), startOfDay(now).AddDate(0, 0, 1), nil
+	case "yesterday":
+		return startOfDay(now).AddDate(0, 0, -1), startOfDay(now), nil
+	case "this-week":
+		return startOfWeek(now), startOfWeek(now).AddDate(0, 0, 7), nil
+	case "last-week":
+		return startOfWeek(now).AddDate(0, 0, -7), startOfWeek(now), nil
+	case "this-month":
+		return startOfMonth(now), startOfMonth(now).AddDate(0, 1, 0), nil
+	case "last-month":
+		return startOfMonth(now).AddDate(0, -1, 0), startOfMonth(now), nil
+	case "this-year":
+		return startOfYear(now), startOfYear(now).AddDate(1, 0, 0), nil
+	case "last-year":
+		return startOfYear(now).AddDate(-1, 0, 0), startOfYear(now), nil
+	}
+
+	for _, format := range dateFormats {
+		t, err := time.ParseInLocation(format, s, now.Location())
+		if err != nil {
+			continue
+		}
+		switch format {
+		case "2006-01-02":
+			return t, t.AddDate(0, 0, 1), nil
+		case "2006-01":
+			return t, t.AddDate(0, 1, 0), nil
+		case "2006":
+			return t, t.AddDate(1, 0, 0), nil
+		}
+		return t, t, nil
+	}
+	return time.Time{}, time.Time{}, errors.New("Unable to parse")
+}
+
+// dateRangeQuery - filters field on dates since and until. Either
+// may be empty. Returns nil when no range is set.
+func dateRangeQuery(field string, since string, until string) query.Query {
+	if since == "" && until == "" {
+		return nil
+	}
+	now := time.Now()
+	var start, end time.Time
+	var err error
+	if since != "" {
+		if start, _, err = parseDate(since, now); err != nil {
+			ThrowError(fmt.Sprintf("Invalid date '%s'; Use a date (2020-01-31), offset (7d, 3w, 6m, 1y), or period (last-month)", since), 1)
+		}
+	}
+	if until != "" {
+		if _, end, err = parseDate(until, now); err != nil {
+			ThrowError(fmt.Sprintf("Invalid date '%s'; Use a date (2020-01-31), offset (7d, 3w, 6m, 1y), or period (last-month)", until), 1)
+		}
+	}
+	q := query.NewDateRangeQuery(start, end)
+	q.SetField(field)
+	return q
+}
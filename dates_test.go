@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2020, 3, 18, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		s         string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"12h", now.Add(-12 * time.Hour), now.Add(-12 * time.Hour)},
+		{"7d", now.AddDate(0, 0, -7), now.AddDate(0, 0, -7)},
+		{"3w", now.AddDate(0, 0, -21), now.AddDate(0, 0, -21)},
+		{"6m", now.AddDate(0, -6, 0), now.AddDate(0, -6, 0)},
+		{"1y", now.AddDate(-1, 0, 0), now.AddDate(-1, 0, 0)},
+		{"now", now, now},
+		{"today", day(2020, 3, 18), day(2020, 3, 19)},
+		{"Yesterday", day(2020, 3, 17), day(2020, 3, 18)},
+		{"this-week", day(2020, 3, 16), day(2020, 3, 23)},
+		{"last-week", day(2020, 3, 9), day(2020, 3, 16)},
+		{"this-month", day(2020, 3, 1), day(2020, 4, 1)},
+		{"last-month", day(2020, 2, 1), day(2020, 3, 1)},
+		{"this-year", day(2020, 1, 1), day(2021, 1, 1)},
+		{"last-year", day(2019, 1, 1), day(2020, 1, 1)},
+		{"2020-01-31", day(2020, 1, 31), day(2020, 2, 1)},
+		{"2019-12", day(2019, 12, 1), day(2020, 1, 1)},
+		{"2019", day(2019, 1, 1), day(2020, 1, 1)},
+		{"2020-01-31 10:15", time.Date(2020, 1, 31, 10, 15, 0, 0, time.UTC), time.Date(2020, 1, 31, 10, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		start, end, err := parseDate(tt.s, now)
+		if err != nil {
+			t.Errorf("parseDate(%q): %s", tt.s, err)
+			continue
+		}
+		if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+			t.Errorf("parseDate(%q) = %v, %v; want %v, %v", tt.s, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+
+	for _, s := range []string{"", "soon", "7x", "2020-13-01"} {
+		if _, _, err := parseDate(s, now); err == nil {
+			t.Errorf("parseDate(%q) should fail", s)
+		}
+	}
+}
@@ -12,25 +12,78 @@ import (
 var dbIdx = *openDb()
 var libDb = fmt.Sprintf("%s/db", getLibraryDirectory())
 
+// Version of the index mapping and records. Libraries
+// with an older version are migrated when opened.
+const indexVersion = "6"
+
+var indexVersionKey = []byte("gg_version")
+
 // openDb
 // This function will initialize a new db if one does
 // not exist or open an existing one.
 func openDb() *bleve.Index {
 	if _, err := os.Stat(libDb); os.IsNotExist(err) {
-		mapping := bleve.NewIndexMapping()
-		dbIdx, err := bleve.New(libDb, mapping)
+		dbIdx, err := bleve.New(libDb, indexMapping())
 		if err != nil {
 			ThrowError("Error creating library", 1)
 		}
+		dbIdx.SetInternal(indexVersionKey, []byte(indexVersion))
 		return &dbIdx
 	}
 	index, err := bleve.Open(libDb)
 	if err != nil {
 		ThrowError("Error opening library", 1)
 	}
+	if version, _ := index.GetInternal(indexVersionKey); string(version) != indexVersion {
+		index = migrateDb(index)
+	}
 	return &index
 }
 
+// migrateDb - rebuilds an index with the current mapping
+// from the records it stores, without fetching gists again.
+func migrateDb(old bleve.Index) bleve.Index {
+	boldMsg("Updating library...\n")
+	dc, _ := old.DocCount()
+	sr := bleve.NewSearchRequest(query.NewMatchAllQuery())
+	sr.Fields = []string{"*"}
+	sr.Size = int(dc)
+	results, err := old.Search(sr)
+	if err != nil {
+		ThrowError("Error updating library. Run 'gg sync --rebuild'", 1)
+	}
+
+	migratePath := fmt.Sprintf("%s.migrate", libDb)
+	os.RemoveAll(migratePath)
+	index, err := bleve.New(migratePath, indexMapping())
+	if err != nil {
+		ThrowError("Error updating library. Run 'gg sync --rebuild'", 1)
+	}
+	collections := loadCollections()
+	batch := index.NewBatch()
+	for _, doc := range results.Hits {
+		sn := applyCollections(refreshSnippet(snippetFromDoc(doc)), collections)
+		batch.Index(sn.ID, sn)
+	}
+	if err := index.Batch(batch); err != nil {
+		ThrowError("Error updating library. Run 'gg sync --rebuild'", 1)
+	}
+	index.SetInternal(indexVersionKey, []byte(indexVersion))
+	index.Close()
+	old.Close()
+
+	// Replace the old index
+	os.RemoveAll(libDb)
+	if err := os.Rename(migratePath, libDb); err != nil {
+		ThrowError("Error updating library. Run 'gg sync --rebuild'", 1)
+	}
+	index, err = bleve.Open(libDb)
+	if err != nil {
+		ThrowError("Error opening library", 1)
+	}
+	return index
+}
+
 func queryGists(docIds []string) *bleve.SearchResult {
 	sr := bleve.NewSearchRequest(query.NewDocIDQuery(docIds))
 	results, err := dbIdx.Search(sr)
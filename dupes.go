@@ -0,0 +1,276 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/query"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Number of words in a shingle
+const shingleSize = 5
+
+type dupeGist struct {
+	gist     *search.DocumentMatch
+	idx      int
+	hash     [32]byte
+	shingles map[uint64]bool
+}
+
+// dupeCluster - gists with the same or similar content.
+// The first gist is the oldest and is treated as the original.
+// Each copy is listed with its closest match in the cluster,
+// which is what linked it to the cluster.
+type dupeCluster struct {
+	gists      []*dupeGist
+	match      []*dupeGist
+	similarity []float64
+}
+
+// gistContents - file contents ordered by filename
+func gistContents(gist *search.DocumentMatch) []string {
+	fileset := parseGistFiles(gist)
+	filenames := make([]string, 0, len(fileset))
+	for filename := range fileset {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	contents := make([]string, len(filenames))
+	for i, filename := range filenames {
+		contents[i] = fileset[filename]["content"]
+	}
+	return contents
+}
+
+// contentHash - hashes file contents regardless of filenames
+func contentHash(contents []string) [32]byte {
+	sorted := append([]string{}, contents...)
+	sort.Strings(sorted)
+	return sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+}
+
+// shingles - hashes of each run of shingleSize words; whitespace
+// and case are ignored.
+func shingles(contents []string) map[uint64]bool {
+	words := strings.Fields(strings.ToLower(strings.Join(contents, "\n")))
+	result := map[uint64]bool{}
+	for i := 0; i == 0 || i+shingleSize <= len(words); i++ {
+		end := i + shingleSize
+		if end > len(words) {
+			end = len(words)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:end], " ")))
+		result[h.Sum64()] = true
+	}
+	return result
+}
+
+// jaccard - similarity of two sets of shingles
+func jaccard(a map[uint64]bool, b map[uint64]bool) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+func similarity(a *dupeGist, b *dupeGist) float64 {
+	if a.hash == b.hash {
+		return 1
+	}
+	return jaccard(a.shingles, b.shingles)
+}
+
+// findDupes - clusters gists with identical content or
+// a similarity of at least threshold
+func findDupes(threshold float64) []dupeCluster {
+	dc, _ := dbIdx.DocCount()
+	sr := bleve.NewSearchRequest(query.NewMatchAllQuery())
+	sr.Size = int(dc)
+	sr.Fields = []string{"*"}
+	sr.SortBy([]string{"CreatedAt", "IDX"})
+	results, err := dbIdx.Search(sr)
+	if err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+
+	gists := make([]*dupeGist, len(results.Hits))
+	for i, gist := range results.Hits {
+		contents := gistContents(gist)
+		gists[i] = &dupeGist{
+			gist:     gist,
+			idx:      int(gist.Fields["IDX"].(float64)),
+			hash:     contentHash(contents),
+			shingles: shingles(contents),
+		}
+	}
+
+	// Union-find; the root of each set is its oldest gist
+	parent := make([]int, len(gists))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	// Closest match of each gist
+	match := make([]int, len(gists))
+	matchSimilarity := make([]float64, len(gists))
+	for i := range gists {
+		for j := i + 1; j < len(gists); j++ {
+			if sim := similarity(gists[i], gists[j]); sim >= threshold {
+				if sim > matchSimilarity[i] {
+					match[i], matchSimilarity[i] = j, sim
+				}
+				if sim > matchSimilarity[j] {
+					match[j], matchSimilarity[j] = i, sim
+				}
+				ri, rj := root(i), root(j)
+				if ri < rj {
+					parent[rj] = ri
+				} else if rj < ri {
+					parent[ri] = rj
+				}
+			}
+		}
+	}
+
+	members := map[int][]int{}
+	var roots []int
+	for i := range gists {
+		r := root(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], i)
+	}
+
+	var clusters []dupeCluster
+	for _, r := range roots {
+		if len(members[r]) < 2 {
+			continue
+		}
+		var cluster dupeCluster
+		for _, i := range members[r] {
+			cluster.gists = append(cluster.gists, gists[i])
+			cluster.match = append(cluster.match, gists[match[i]])
+			cluster.similarity = append(cluster.similarity, matchSimilarity[i])
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+func dupeClusterTable(cluster dupeCluster) {
+	data := make([][]string, len(cluster.gists))
+	for i, g := range cluster.gists {
+		var sim string
+		switch {
+		case i == 0:
+			sim = "original"
+		case g.hash == cluster.match[i].hash:
+			sim = fmt.Sprintf("identical to %v", cluster.match[i].idx)
+		default:
+			sim = fmt.Sprintf("%.0f%% to %v", cluster.similarity[i]*100, cluster.match[i].idx)
+		}
+		data[i] = []string{
+			fmt.Sprintf("%v", g.idx),
+			g.gist.Fields["Owner"].(string),
+			g.gist.Fields["Description"].(string),
+			sim,
+		}
+	}
+
+	renderTable([]string{"ID", "Owner", "Description", "Similarity"}, data)
+}
+
+// dupeDiff - prints a side-by-side diff of two gists
+func dupeDiff(a *dupeGist, b *dupeGist) {
+	var files []string
+	for _, g := range []*dupeGist{a, b} {
+		tmpfile, err := ioutil.TempFile("", fmt.Sprintf("gist.%v.*", g.idx))
+		check(err)
+		defer os.Remove(tmpfile.Name())
+		tmpfile.WriteString(strings.Join(gistContents(g.gist), "\n"))
+		tmpfile.Close()
+		files = append(files, tmpfile.Name())
+	}
+
+	width, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width < 40 {
+		width = 160
+	}
+	boldMsg(fmt.Sprintf("%v <> %v\n", a.idx, b.idx))
+	cmd := exec.Command("diff", "--side-by-side", fmt.Sprintf("--width=%v", width), files[0], files[1])
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// diff exits with 1 when files differ
+	cmd.Run()
+	fmt.Println()
+}
+
+// dupes - lists duplicate gists and optionally diffs
+// or removes the copies
+func dupes(threshold float64, showDiff bool, remove bool) {
+	if threshold <= 0 || threshold > 1 {
+		ThrowError("--threshold must be between 0 and 1", 1)
+	}
+	clusters := findDupes(threshold)
+	if len(clusters) == 0 {
+		successMsg("No duplicates found\n")
+		return
+	}
+
+	var login string
+	if remove {
+		config, err := getConfig()
+		if err != nil {
+			ThrowError("Login to remove gists", 1)
+		}
+		login = config.Login
+	}
+
+	for i, cluster := range clusters {
+		boldMsg(fmt.Sprintf("Cluster %v: %v gists\n", i+1, len(cluster.gists)))
+		dupeClusterTable(cluster)
+		fmt.Println()
+		for j, g := range cluster.gists[1:] {
+			// Copies are compared with their closest match
+			if showDiff {
+				dupeDiff(cluster.match[j+1], g)
+			}
+			if remove {
+				if g.gist.Fields["Owner"].(string) != login {
+					errorMsg(fmt.Sprintf("Skipping %v; owned by %s\n", g.idx, g.gist.Fields["Owner"]))
+					continue
+				}
+				if confirmPrompt(fmt.Sprintf("Remove %v: %s?", g.idx, g.gist.Fields["Description"])) {
+					rmGist(g.idx)
+				}
+			}
+		}
+	}
+}
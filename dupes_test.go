@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		contents []string
+		want     int
+	}{
+		// Content shorter than a shingle is a single shingle
+		{[]string{""}, 1},
+		{nil, 1},
+		{[]string{"echo hello"}, 1},
+		{[]string{"one two three four five"}, 1},
+		{[]string{"one two three four five six"}, 2},
+		// Whitespace and case are ignored
+		{[]string{"One  two\nthree", "four five six"}, 2},
+		{[]string{"a a a a a a a"}, 1},
+	}
+	for _, tt := range tests {
+		if got := len(shingles(tt.contents)); got != tt.want {
+			t.Errorf("len(shingles(%q)) = %v; want %v", tt.contents, got, tt.want)
+		}
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	s := func(content string) map[uint64]bool {
+		return shingles([]string{content})
+	}
+	tests := []struct {
+		a    map[uint64]bool
+		b    map[uint64]bool
+		want float64
+	}{
+		{map[uint64]bool{}, map[uint64]bool{}, 0},
+		{s("echo hello"), map[uint64]bool{}, 0},
+		{s(""), s(""), 1},
+		{s("echo hello"), s("ECHO   hello"), 1},
+		{s("echo hello"), s("echo goodbye"), 0},
+		{s("one two three four five six"), s("one two three four five seven"), 1.0 / 3},
+		{s("one two three four five six"), s("two three four five six"), 0.5},
+	}
+	for _, tt := range tests {
+		if got := jaccard(tt.a, tt.b); got != tt.want {
+			t.Errorf("jaccard(%v, %v) = %v; want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := jaccard(tt.b, tt.a); got != tt.want {
+			t.Errorf("jaccard(%v, %v) = %v; want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+)
+
+// Fields counted with --facets
+var facetFields = []struct {
+	name  string
+	field string
+}{
+	{"tag", "Tags"},
+	{"language", "Language"},
+	{"owner", "Owner"},
+	{"visibility", "Public"},
+}
+
+type facetCount struct {
+	Term  string `json:"Term" yaml:"Term"`
+	Count int    `json:"Count" yaml:"Count"`
+}
+
+// facetGroup - counts for one field over all results of a query
+type facetGroup struct {
+	Name   string       `json:"Name" yaml:"Name"`
+	Counts []facetCount `json:"Counts" yaml:"Counts"`
+}
+
+// addFacets - requests facet counts for --facets
+func addFacets(sr *bleve.SearchRequest, search *searchQuery) {
+	if search.facets == false {
+		return
+	}
+	for _, f := range facetFields {
+		sr.AddFacet(f.name, bleve.NewFacetRequest(f.field, 100))
+	}
+}
+
+// resultFacets - returns facet counts in the order of facetFields
+func resultFacets(results *bleve.SearchResult) []facetGroup {
+	var groups []facetGroup
+	for _, f := range facetFields {
+		facet, ok := results.Facets[f.name]
+		if !ok {
+			continue
+		}
+		group := facetGroup{Name: f.name, Counts: []facetCount{}}
+		for _, term := range facet.Terms {
+			label := term.Term
+			switch f.name {
+			case "tag":
+				label = "#" + label
+			case "visibility":
+				label = ifelse(label == "t", "public", "private")
+			}
+			group.Counts = append(group.Counts, facetCount{label, term.Count})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// facetSummary - prints facet counts below the result table
+func facetSummary(results *bleve.SearchResult) {
+	for _, group := range resultFacets(results) {
+		if len(group.Counts) == 0 {
+			continue
+		}
+		counts := make([]string, len(group.Counts))
+		for i, c := range group.Counts {
+			counts[i] = fmt.Sprintf("%s %v", c.Term, c.Count)
+		}
+		fmt.Fprintf(os.Stdout, "%s %s\n", blueText.Sprintf("%-11s", strings.Title(group.Name)+":"), strings.Join(counts, ", "))
+	}
+}
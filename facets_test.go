@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve"
+)
+
+func TestResultFacets(t *testing.T) {
+	index, err := bleve.NewMemOnly(indexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer index.Close()
+	snippets := []Snippet{
+		{ID: "a", Public: trueFalse(true), Tags: []string{"AWS"}},
+		{ID: "b", Public: trueFalse(true)},
+		{ID: "c", Public: trueFalse(false), Tags: []string{"aws"}},
+	}
+	for _, sn := range snippets {
+		if err := index.Index(sn.ID, sn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sr := bleve.NewSearchRequest(bleve.NewMatchAllQuery())
+	addFacets(sr, &searchQuery{facets: true})
+	results, err := index.Search(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]facetCount{
+		"tag":        {{"#aws", 2}},
+		"visibility": {{"public", 2}, {"private", 1}},
+	}
+	got := map[string][]facetCount{}
+	for _, group := range resultFacets(results) {
+		got[group.Name] = group.Counts
+	}
+	for name, counts := range want {
+		if !reflect.DeepEqual(got[name], counts) {
+			t.Errorf("resultFacets() %s = %v; want %v", name, got[name], counts)
+		}
+	}
+}
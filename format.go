@@ -1,65 +1,73 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/blevesearch/bleve"
-	"github.com/blevesearch/bleve/search/query"
+	"github.com/blevesearch/bleve/search"
 	aw "github.com/deanishe/awgo"
 	"github.com/olekukonko/tablewriter"
 	"golang.org/x/crypto/ssh/terminal"
 )
 
-func caseInsensitiveReplace(subject string, search string, replace string) string {
-	searchRegex := regexp.MustCompile("(?i)" + regexp.QuoteMeta(search))
-	pos := searchRegex.FindStringIndex(subject)
-	if pos != nil {
-		start, stop := pos[0], pos[1]
-		return subject[:start] + highlightText.Sprintf(subject[start:stop]) + subject[stop:len(subject)]
-	}
-	return subject
-}
-
-func highlightTerms(s string, terms []string) string {
-	for _, term := range terms {
-		s = caseInsensitiveReplace(s, term, highlightText.Sprint(term))
-	}
-	return s
-}
-
 func removeFields(header []string, tableData [][]string, omitFields []string) ([]string, [][]string) {
 	// Removes fields from the header and table
-
-	// Remove fields from header
-	n := 0
-	for _, field := range header {
+	var keep []int
+	for idx, field := range header {
 		if contains(omitFields, field) == false {
-			header[n] = field
-			n++
+			keep = append(keep, idx)
 		}
 	}
-	header = header[:n]
 
 	// Remove fields from table
-	for ridx := range tableData {
-		n = 0
-		for idx, field := range header {
-			if contains(omitFields, field) == false {
-				tableData[ridx][n] = tableData[ridx][idx]
-				n++
-			}
+	for ridx, row := range tableData {
+		kept := make([]string, len(keep))
+		for n, idx := range keep {
+			kept[n] = row[idx]
 		}
-		tableData[ridx] = tableData[ridx][:n]
+		tableData[ridx] = kept
+	}
+
+	// Remove fields from header
+	kept := make([]string, len(keep))
+	for n, idx := range keep {
+		kept[n] = header[idx]
+	}
+	return kept, tableData
+}
+
+// Header of the result table
+func resultHeader(columns []tableColumn, isQuery bool) []string {
+	var header []string
+	for _, column := range columns {
+		header = append(header, column.header)
+	}
+	if isQuery {
+		header = append(header, "Score")
+	}
+	return header
+}
+
+// A single row of the result table; pos starts at 1
+func resultRow(gist *search.DocumentMatch, pos int, columns []tableColumn, isQuery bool) []string {
+	var row []string
+	for _, column := range columns {
+		row = append(row, column.value(gist, pos))
+	}
+	if isQuery {
+		row = append(row, fmt.Sprintf("%1.3f", gist.Score))
 	}
-	return header, tableData
+	return row
 }
 
 // Generate a result table
-func resultTable(results *bleve.SearchResult, isQuery bool, highlightTermSet []string) {
+func resultTable(results *bleve.SearchResult, isQuery bool, offset int) {
 
 	/*
 		Format
@@ -69,68 +77,48 @@ func resultTable(results *bleve.SearchResult, isQuery bool, highlightTermSet []s
 
 	var colWidth int
 
-	tableData := make([][]string, len(results.Hits))
-	for idx, gist := range results.Hits {
-
-		updatedAt := strings.Split(gist.Fields["UpdatedAt"].(string), "T")[0]
-
-		tableData[idx] = []string{
-			fmt.Sprintf("%v", gist.Fields["IDX"]),
-			ifelse(gist.Fields["Starred"].(string) == "T", "⭐", ""),
-			ifelse(gist.Fields["Public"].(string) == "F", "🔒", ""),
-			highlightTerms(fmt.Sprintf("%.60v", gist.Fields["Description"].(string)), highlightTermSet),
-			highlightTerms(fmt.Sprintf("%v", gist.Fields["Filename"]), highlightTermSet),
-			highlightTerms(fmt.Sprintf("%v", gist.Fields["Language"]), highlightTermSet),
-			highlightTerms(gist.Fields["Owner"].(string), highlightTermSet),
-			string(fmt.Sprintf("%v", gist.Fields["NLines"].(float64))),
-			updatedAt,
-		}
+	columns := resultColumns()
+	var header = resultHeader(columns, isQuery)
+	descIdx := indexOf(header, "Description")
 
-		if isQuery {
-			tableData[idx] = append(tableData[idx], fmt.Sprintf("%1.3f", gist.Score))
+	var tableData [][]string
+	for idx, gist := range results.Hits {
+		row := resultRow(gist, idx+1, columns, isQuery)
+		tableData = append(tableData, row)
+
+		// Show matching file content under the hit
+		if fragment := matchFragment(gist); fragment != "" && descIdx >= 0 {
+			matchRow := make([]string, len(row))
+			matchRow[descIdx] = fmt.Sprintf("%s %s", blueText.Sprint("Match:"), fragment)
+			tableData = append(tableData, matchRow)
 		}
 	}
 
-	// Render results
-	table := tablewriter.NewWriter(os.Stdout)
+	// Render results; long results are paged
+	var out bytes.Buffer
+	table := tablewriter.NewWriter(&out)
 
 	table.SetAutoWrapText(false)
 
 	/*
 		Header
 	*/
-	var header = []string{"ID", "⭐", "🔒", "Description", "Filename", "Language", "Owner", "n", "Updated"}
-	if isQuery {
-		header = append(header, "Score")
-	}
+	// Drop columns that do not fit the terminal
+	header, tableData = fitColumns(header, tableData, xsize)
 
 	colWidth = (xsize / len(header))
 
-	var omitFields []string
-	switch {
-	case between(xsize, 100, 125):
-		omitFields = []string{"Updated", "Owner"}
-	case between(xsize, 80, 100):
-		omitFields = []string{"Updated", "Owner", "Language"}
-	}
-	if xsize < 125 {
-		header, tableData = removeFields(header, tableData, omitFields)
-	}
-
 	table.SetAutoFormatHeaders(false)
 	table.SetHeader(header)
 
-	var headerColors []tablewriter.Colors
-	headerColors = make([]tablewriter.Colors, len(header))
-	for i := 0; i < len(header); i++ {
-		headerColors[i] = tablewriter.Colors{tablewriter.Bold}
-	}
-	table.SetHeaderColor(headerColors...)
+	setHeaderColors(table, len(header))
 	table.SetHeaderLine(true)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 
 	table.SetColWidth(colWidth)
-	table.SetColMinWidth(3, int(float32(colWidth)*2.5))
+	if descIdx = indexOf(header, "Description"); descIdx >= 0 {
+		table.SetColMinWidth(descIdx, int(float32(colWidth)*2.5))
+	}
 	table.SetColumnSeparator("\t")
 	table.SetCenterSeparator("\t")
 	// Give Description 2x width
@@ -139,45 +127,131 @@ func resultTable(results *bleve.SearchResult, isQuery bool, highlightTermSet []s
 	//table.SetTablePadding("")
 	table.SetAutoWrapText(false)
 	table.Render()
-	blueText.Printf("Showing %v Hit%s of %v Results\n", len(results.Hits), ifelse(results.Total != 1, "s", ""), results.Total)
+	resultFooter(&out, results, offset)
+	page(out.String())
 }
 
-/*
-	Summarize a field
-*/
-func fieldSummaryTable(field string, data [][]string) {
+func hasNextPage(results *bleve.SearchResult) bool {
+	return uint64(results.Request.From+len(results.Hits)) < results.Total
+}
+
+// Arguments of the command that listed the results
+var commandArgs = os.Args
+
+// nextPageCommand - the command line used to show the next page
+func nextPageCommand(page int) string {
+	// Drop --page from the original arguments
+	var args []string
+	for i := 1; i < len(commandArgs); i++ {
+		arg := commandArgs[i]
+		if contains([]string{"--page", "-page", "-p"}, arg) {
+			i++
+			continue
+		} else if strings.HasPrefix(arg, "--page=") || strings.HasPrefix(arg, "-page=") || strings.HasPrefix(arg, "-p=") {
+			continue
+		}
+		if strings.ContainsAny(arg, " \t'\"#") {
+			arg = strconv.Quote(arg)
+		}
+		args = append(args, arg)
+	}
+	// Options must follow the command and precede the query
+	pos := 0
+	if len(args) > 0 && (contains(queryReserve, args[0]) || strings.HasPrefix(args[0], "@")) {
+		pos = 1
+	}
+	next := append([]string{"gg"}, args[:pos]...)
+	next = append(next, "--page", strconv.Itoa(page))
+	return strings.Join(append(next, args[pos:]...), " ")
+}
+
+// resultFooter - pages are counted from --offset, which is
+// kept by the next page command
+func resultFooter(out io.Writer, results *bleve.SearchResult, offset int) {
+	from := results.Request.From
+	size := results.Request.Size
+	if offset < 0 {
+		offset = 0
+	}
+	if from == offset && hasNextPage(results) == false {
+		blueText.Fprintf(out, "Showing %v Hit%s of %v Results\n", len(results.Hits), ifelse(results.Total != 1, "s", ""), results.Total)
+		return
+	}
+	page := (from-offset)/size + 1
+	pages := (int(results.Total) - offset + size - 1) / size
+	blueText.Fprintf(out, "Showing %v-%v of %v Results (page %v of %v)\n", from+1, from+len(results.Hits), results.Total, page, pages)
+	if hasNextPage(results) && outputPipe() == false {
+		fmt.Fprintf(out, "Next page: %s\n", nextPageCommand(page+1))
+	}
+}
+
+// morePrompt - asks whether to show the next page
+func morePrompt() bool {
+	boldMsg("-- More -- [enter: next page, q: quit] ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	return strings.ToLower(strings.TrimSpace(line)) != "q"
+}
+
+// confirmPrompt - asks a yes/no question; defaults to no
+func confirmPrompt(msg string) bool {
+	boldMsg(fmt.Sprintf("%s [y/N] ", msg))
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	return contains([]string{"y", "yes"}, strings.ToLower(strings.TrimSpace(line)))
+}
+
+// renderTable - prints a table with a bold header and tab separated columns
+func renderTable(header []string, data [][]string) {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoFormatHeaders(false)
-	table.SetHeader([]string{field, "Count"})
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Bold},
-	)
+	table.SetHeader(header)
+	setHeaderColors(table, len(header))
 	table.SetHeaderLine(true)
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 
 	table.SetBorders(tablewriter.Border{Left: false, Top: false, Right: false, Bottom: false})
+	table.SetAutoWrapText(false)
 	table.AppendBulk(data)
 	table.SetColumnSeparator("\t")
 	table.SetCenterSeparator("\t")
 	table.Render()
 }
 
+/*
+Summarize a field
+*/
+func fieldSummaryTable(field string, data [][]string) {
+	renderTable([]string{field, "Count"}, data)
+}
+
 func queryGistsAlfred(alfredQuery string) {
-	squery.limit = 100
-	squery.status = "all"
+	alfredSearch(&squery, alfredQuery)
+	ls(&squery)
+}
+
+// alfredSearch - sets up the search for a query typed in Alfred.
+// Gists used most often and most recently are listed first, with
+// ties (including gists that were never used) ranked by relevance.
+func alfredSearch(q *searchQuery, alfredQuery string) {
+	q.limit = 100
+	q.status = "all"
+	q.sort = "frecency"
 	switch {
 	// Starred gists
 	case strings.HasPrefix(alfredQuery, "⭐"):
-		squery.starred = true
+		q.starred = true
 		var subQuery = strings.SplitAfter(alfredQuery, " ")
 		if len(subQuery) > 0 {
-			squery.term = subQuery[0]
+			q.term = subQuery[0]
 		}
 	default:
-		squery.term = alfredQuery
+		q.term = alfredQuery
 	}
-	ls(&squery)
 }
 
 func fieldSummaryAlfred(field string, data [][]string) {
@@ -211,6 +285,7 @@ func fieldSummaryAlfred(field string, data [][]string) {
 			squery.term = subQuery[1] // if empty (""); term does nothing
 			squery.status = "all"
 			squery.limit = 100
+			squery.sort = "frecency"
 			if qPrefix == "#" {
 				squery.tag = row[0]
 			} else if qPrefix == "~" {
@@ -224,6 +299,39 @@ func fieldSummaryAlfred(field string, data [][]string) {
 	}
 }
 
+func savedSearchAlfred(alfredQuery string) {
+	saved := loadSavedSearches()
+	var subQuery = strings.SplitN(alfredQuery, " ", 2)
+	var name = strings.TrimPrefix(subQuery[0], "@")
+	if _, ok := saved[name]; ok && len(subQuery) > 1 {
+		// Run saved search with any additional terms
+		args := savedSearchArgs(name, strings.Fields(subQuery[1]))
+		q, err := parseQueryArgs(args)
+		if err != nil {
+			return
+		}
+		squery = q
+		// Alfred lists more results, by frecency, unless the search
+		// sets its own limit or order
+		if setsFlag(args, &limitFlag) == false {
+			squery.limit = 100
+		}
+		if setsFlag(args, &sortFlag) == false {
+			squery.sort = "frecency"
+		}
+		ls(&squery) // invokes resultListAlfred
+		return
+	}
+	for _, savedName := range savedSearchNames(saved) {
+		if strings.HasPrefix(savedName, name) {
+			wf.NewItem("@" + savedName).
+				Icon(savedIcon).
+				Autocomplete(fmt.Sprintf("@%s ", savedName)).
+				Subtitle(strings.Join(saved[savedName], " "))
+		}
+	}
+}
+
 func resultListAlfred(results *bleve.SearchResult) {
 
 	for _, gist := range results.Hits {
@@ -251,7 +359,8 @@ func resultListAlfred(results *bleve.SearchResult) {
 
 		// Get Gist Content
 		gistText := GistToText(gist)
-		subtitleText := fmt.Sprintf("%s%s%s", statusText, filenameText, truncateString(gistText, 100))
+		noteSubtitle := ifelse(fieldString(gist, "Note") != "", fmt.Sprintf("📝 %s | ", fieldString(gist, "Note")), "")
+		subtitleText := fmt.Sprintf("%s%s%s%s", statusText, filenameText, noteSubtitle, truncateString(gistText, 100))
 
 		icon := resolveIcon(gist.Fields["Language"])
 		it := wf.NewItem(gist.Fields["Description"].(string)).
@@ -261,6 +370,7 @@ func resultListAlfred(results *bleve.SearchResult) {
 			Subtitle(subtitleText).
 			Arg(gistText).
 			Var("title", fmt.Sprintf("'%s'", gist.Fields["Description"].(string))).
+			Var("idx", fmt.Sprintf("%v", int(gist.Fields["IDX"].(float64)))).
 			Valid(true)
 
 		it.Cmd().
@@ -273,13 +383,25 @@ func resultListAlfred(results *bleve.SearchResult) {
 			Arg(fmt.Sprintf("%v", int(gist.Fields["IDX"].(float64)))).
 			Valid(true)
 
+		it.Ctrl().
+			Subtitle("Show related gists").
+			Arg(fmt.Sprintf("related:%v", int(gist.Fields["IDX"].(float64)))).
+			Var("action", "related").
+			Valid(true)
+
 	}
 }
 
-func fieldSummary(field string) {
+func fieldSummary(field string, search *searchQuery) {
 	// Calculates frequencies for a given field
-	facet := bleve.NewFacetRequest(field, 100000)
-	query := query.NewMatchAllQuery()
+	// among gists matching the active filters
+	facetField := field
+	if field == "Tags" {
+		// Count parent tags (aws) along with children (aws/s3)
+		facetField = "TagPaths"
+	}
+	facet := bleve.NewFacetRequest(facetField, 100000)
+	query := andQuery(nil, filterQueries(search))
 	searchRequest := bleve.NewSearchRequest(query)
 	searchRequest.AddFacet("count", facet)
 	searchResults, err := dbIdx.Search(searchRequest)
@@ -293,38 +415,62 @@ func fieldSummary(field string) {
 		data[idx] = []string{val.Term, strconv.Itoa(val.Count)}
 	}
 
-	if outputFormat == "console" {
+	if outputFormat == "console" && field == "Tags" {
+		tagTreeTable(data)
+	} else if outputFormat == "console" {
 		fieldSummaryTable(field, data)
 	} else if outputFormat == "alfred" {
 		fieldSummaryAlfred(field, data)
+	} else {
+		writeSummary(field, data)
 	}
 }
 
 func outputGist(gistIdx int) {
 	gist := lookupGist(gistIdx)
 	fileset := parseGistFiles(gist)
-
+	recordUsage(gist, "open")
+
+	// Output is paged when longer than the terminal. The note and
+	// file headers are only written when stdout is a terminal, so
+	// redirected output contains just the files.
+	var out bytes.Buffer
+	isTerminal := terminal.IsTerminal(int(os.Stdout.Fd()))
+	if note := fieldString(gist, "Note"); note != "" && isTerminal {
+		fmt.Fprintf(&out, "%s %s\n", noteText.Sprint("Note:"), note)
+	}
 	for _, file := range fileset {
-		var xsize, _, _ = terminal.GetSize(0)
-		var line = strings.Repeat("-", xsize-len(file["filename"])-50)
+		var xsize, _, _ = terminal.GetSize(int(os.Stdout.Fd()))
+		var line = strings.Repeat("-", ifInt(xsize > len(file["filename"])+50, xsize-len(file["filename"])-50, 0))
 		var isPrivate string
 		if gist.Fields["Public"] == "false" {
 			isPrivate = "🔒"
 		} else {
 			isPrivate = "-"
 		}
-		if outputPipe() {
-			fmt.Print(file["content"])
+		if isTerminal == false && lineNumbers {
+			highlightNumbered(&out, file["filename"], file["content"], "noop", chromaStyle())
+		} else if isTerminal == false {
+			out.WriteString(file["content"])
 		} else {
-			errlog.Printf("%s%s%s%s", greenText.Sprint(file["filename"]), isPrivate, line, file["language"])
-			highlight(os.Stdout, file["filename"], file["content"], "terminal16m", "fruity")
-			fmt.Fprintf(os.Stderr, "\n\n")
+			fmt.Fprintf(&out, "%s%s%s%s\n", greenText.Sprint(file["filename"]), isPrivate, line, file["language"])
+			if lineNumbers {
+				highlightNumbered(&out, file["filename"], file["content"], chromaFormatter(), chromaStyle())
+			} else {
+				highlight(&out, file["filename"], file["content"], chromaFormatter(), chromaStyle())
+			}
+			out.WriteString("\n\n")
 		}
 	}
+	page(out.String())
 }
 
 func fetchGistContent(gistIdx int) string {
-	gist := lookupGist(gistIdx)
+	return gistContent(lookupGist(gistIdx))
+}
+
+// gistContent - the files of a gist joined for copying
+func gistContent(gist *search.DocumentMatch) string {
 	fileset := parseGistFiles(gist)
 	var result string
 	for _, file := range fileset {
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+)
+
+func TestAlfredSearch(t *testing.T) {
+	tests := []struct {
+		alfredQuery string
+		term        string
+		starred     bool
+	}{
+		{"", "", false},
+		{"aws upload", "aws upload", false},
+		{"⭐", "⭐", true},
+	}
+	for _, tt := range tests {
+		var q searchQuery
+		alfredSearch(&q, tt.alfredQuery)
+		if q.term != tt.term || q.starred != tt.starred {
+			t.Errorf("alfredSearch(%q) term = %q, starred = %v; want %q, %v", tt.alfredQuery, q.term, q.starred, tt.term, tt.starred)
+		}
+		if q.sort != "frecency" || q.limit != 100 || q.status != "all" {
+			t.Errorf("alfredSearch(%q) sort = %q, limit = %v, status = %q; want frecency, 100, all", tt.alfredQuery, q.sort, q.limit, q.status)
+		}
+		// Typed queries are ranked by frecency, then relevance
+		sr := bleve.NewSearchRequest(bleve.NewMatchAllQuery())
+		sortResults(sr, &q, tt.alfredQuery != "")
+		want := search.ParseSortOrderStrings([]string{"-Frecency", "-_score"})
+		if !reflect.DeepEqual(sr.Sort, want) {
+			t.Errorf("alfredSearch(%q) sorts by %v; want %v", tt.alfredQuery, sr.Sort, want)
+		}
+	}
+}
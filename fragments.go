@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/registry"
+	"github.com/blevesearch/bleve/search"
+	bleveHighlight "github.com/blevesearch/bleve/search/highlight"
+	simpleFragmenter "github.com/blevesearch/bleve/search/highlight/fragmenter/simple"
+	simpleHighlighter "github.com/blevesearch/bleve/search/highlight/highlighter/simple"
+)
+
+// Name of the bleve highlighter used for search results
+const fragmentHighlighter = "gg"
+
+// Number of characters shown in a match fragment
+const fragmentSize = 60
+
+// termFormatter formats bleve fragments using the same
+// color as the rest of the result table.
+type termFormatter struct{}
+
+func (t *termFormatter) Format(f *bleveHighlight.Fragment, orderedTermLocations bleveHighlight.TermLocations) string {
+	var result string
+	curr := f.Start
+	for _, termLocation := range orderedTermLocations {
+		if termLocation == nil {
+			continue
+		}
+		if !termLocation.ArrayPositions.Equals(f.ArrayPositions) {
+			continue
+		}
+		if termLocation.Start < curr {
+			continue
+		}
+		if termLocation.End > f.End {
+			break
+		}
+		result += string(f.Orig[curr:termLocation.Start])
+		result += highlightText.Sprint(string(f.Orig[termLocation.Start:termLocation.End]))
+		curr = termLocation.End
+	}
+	result += string(f.Orig[curr:f.End])
+	return result
+}
+
+func init() {
+	registry.RegisterHighlighter(fragmentHighlighter, func(config map[string]interface{}, cache *registry.Cache) (bleveHighlight.Highlighter, error) {
+		return simpleHighlighter.NewHighlighter(
+			simpleFragmenter.NewFragmenter(fragmentSize),
+			&termFormatter{},
+			simpleHighlighter.DefaultSeparator), nil
+	})
+}
+
+// fieldLocations returns the match locations of a field sorted by position.
+// pos restricts locations to a single value of an array field; use -1 for all.
+func fieldLocations(gist *search.DocumentMatch, field string, pos int) search.Locations {
+	var locations search.Locations
+	for _, termLocations := range gist.Locations[field] {
+		for _, loc := range termLocations {
+			if pos >= 0 && (len(loc.ArrayPositions) == 0 || int(loc.ArrayPositions[0]) != pos) {
+				continue
+			}
+			locations = append(locations, loc)
+		}
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].Start < locations[j].Start
+	})
+	return locations
+}
+
+// highlightLocations highlights the matched byte ranges of s.
+// Locations that fall outside of s (e.g. after truncation) are skipped.
+func highlightLocations(s string, locations search.Locations) string {
+	var result string
+	var curr uint64
+	for _, loc := range locations {
+		if loc.Start < curr || loc.End > uint64(len(s)) {
+			continue
+		}
+		result += s[curr:loc.Start] + highlightText.Sprint(s[loc.Start:loc.End])
+		curr = loc.End
+	}
+	return result + s[curr:]
+}
+
+// highlightField formats a stored field and highlights terms
+// matched within that field only.
+func highlightField(gist *search.DocumentMatch, field string) string {
+	switch val := gist.Fields[field].(type) {
+	case string:
+		return highlightLocations(val, fieldLocations(gist, field, -1))
+	case []interface{}:
+		values := make([]string, len(val))
+		for idx, s := range val {
+			values[idx] = highlightLocations(fmt.Sprintf("%v", s), fieldLocations(gist, field, idx))
+		}
+		return fmt.Sprintf("[%s]", strings.Join(values, " "))
+	}
+	return fmt.Sprintf("%v", gist.Fields[field])
+}
+
+// matchFragment returns the best fragment of file content matching
+// the query on a single line.
+func matchFragment(gist *search.DocumentMatch) string {
+	var fields []string
+	for field := range gist.Fragments {
+		if strings.HasPrefix(field, "Files.") && strings.HasSuffix(field, ".content") {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		for _, fragment := range gist.Fragments[field] {
+			if fragment = strings.Join(strings.Fields(fragment), " "); fragment != "" {
+				return fragment
+			}
+		}
+	}
+	return ""
+}
@@ -22,6 +22,9 @@ var greenText = color.New(color.FgGreen).Add(color.Bold)
 var highlightText = color.New(color.FgGreen).Add(color.Bold).Add(color.Underline)
 var boldUnderline = color.New(color.Underline).Add(color.Bold)
 var blueText = color.New(color.FgBlue).Add(color.Bold)
+var ruleTagText = color.New(color.FgMagenta)
+var noteText = color.New(color.FgYellow).Add(color.Bold)
+var lineNumberText = color.New(color.FgHiBlack)
 var squery = searchQuery{}
 var outputFormat = "console"
 
@@ -53,9 +56,30 @@ func fillQuery(squery *searchQuery, c *cli.Context) {
 	squery.sort = strings.ToLower(c.String("sort"))
 	squery.language = c.String("language")
 	squery.starred = c.Bool("starred")
+	squery.pinned = c.Bool("pinned")
+	squery.collection = c.String("collection")
 	squery.status = c.String("status")
 	squery.limit = c.Int("limit")
+	squery.page = c.Int("page")
+	squery.offset = c.Int("offset")
+	squery.fuzzy = strings.ToLower(c.String("fuzzy"))
+	squery.facets = c.Bool("facets")
+	squery.since = c.String("since")
+	squery.until = c.String("until")
+	squery.createdSince = c.String("created-since")
+	squery.createdUntil = c.String("created-until")
+	squery.minLines = c.Int("min-lines")
+	squery.maxLines = c.Int("max-lines")
+	squery.files = c.String("files")
+	squery.minComments = c.Int("min-comments")
+	squery.minSize = c.String("min-size")
+	squery.maxSize = c.String("max-size")
 	squery.debug = c.Bool("debug")
+	setOutputFormat(c.String("format"))
+	setOutputTemplate(c.String("template"))
+	setTableColumns(c.String("columns"))
+	setHighlight(c.String("style"), c.String("formatter"))
+	noPager = c.Bool("no-pager")
 }
 
 // Flags
@@ -65,10 +89,62 @@ var starredFlag = cli.BoolFlag{
 	Usage:   "Filter by starred snippets",
 }
 
+var pinnedFlag = cli.BoolFlag{
+	Name:  "pinned",
+	Usage: "Filter by pinned gists",
+}
+
+var collectionFlag = cli.StringFlag{
+	Name:  "collection",
+	Value: "",
+	Usage: "Filter by local collection",
+}
+
+var formatFlag = cli.StringFlag{
+	Name:  "format",
+	Value: "console",
+	Usage: "Output format: console, json, jsonl, csv, tsv, or yaml",
+}
+
+var templateFlag = cli.StringFlag{
+	Name:  "template",
+	Value: "",
+	Usage: "Output each gist with a Go template or a template named in the config (e.g. '{{.IDX}} {{.Description}}')",
+}
+
+var columnsFlag = cli.StringFlag{
+	Name:  "columns",
+	Value: "",
+	Usage: "Columns of the result table (e.g. id,star,desc,files,lang,tags,created,comments,size)",
+}
+
+var styleFlag = cli.StringFlag{
+	Name:  "style",
+	Value: "",
+	Usage: "Syntax highlighting style (see gg themes)",
+}
+
+var formatterFlag = cli.StringFlag{
+	Name:  "formatter",
+	Value: "",
+	Usage: "Syntax highlighting colors: terminal, terminal256, terminal16m, or noop",
+}
+
+var noPagerFlag = cli.BoolFlag{
+	Name:  "no-pager",
+	Usage: "Do not use $PAGER (or less) for output longer than the terminal",
+}
+
+var lineNumbersFlag = cli.BoolFlag{
+	Name:    "line-numbers",
+	Aliases: []string{"n"},
+	Usage:   "Show line numbers",
+}
+
 var sortFlag = cli.StringFlag{
 	Name:  "sort",
 	Value: "",
-	Usage: "Sort by field",
+	Usage: "Sort by comma separated fields; prefix with - to reverse (e.g. language,-updated)",
 }
 
 var limitFlag = cli.IntFlag{
@@ -78,6 +154,19 @@ var limitFlag = cli.IntFlag{
 	Usage:   "Max number of results to display",
 }
 
+var pageFlag = cli.IntFlag{
+	Name:    "page",
+	Aliases: []string{"p"},
+	Value:   1,
+	Usage:   "Page of results to display",
+}
+
+var offsetFlag = cli.IntFlag{
+	Name:  "offset",
+	Value: 0,
+	Usage: "Number of results to skip",
+}
+
 var statusFlag = cli.StringFlag{
 	Name:  "status",
 	Value: "all",
@@ -91,23 +180,145 @@ var tagFlag = cli.StringFlag{
 	Usage:   "Filter by tag; omit the # prefix",
 }
 
+var fuzzyFlag = cli.StringFlag{
+	Name:  "fuzzy",
+	Value: "auto",
+	Usage: "Fuzzy matching [off|auto|on]; auto is used when there are no exact matches",
+}
+
+var facetsFlag = cli.BoolFlag{
+	Name:  "facets",
+	Usage: "Show tag, language, owner, and visibility counts for the results",
+}
+
+var sinceFlag = cli.StringFlag{
+	Name:  "since",
+	Usage: "Filter by updated on or after a date (2020-01-31), offset (7d, 3w, 6m, 1y), or period (last-month)",
+}
+
+var untilFlag = cli.StringFlag{
+	Name:  "until",
+	Usage: "Filter by updated before the end of a date, offset, or period",
+}
+
+var createdSinceFlag = cli.StringFlag{
+	Name:  "created-since",
+	Usage: "Filter by created on or after a date, offset, or period",
+}
+
+var createdUntilFlag = cli.StringFlag{
+	Name:  "created-until",
+	Usage: "Filter by created before the end of a date, offset, or period",
+}
+
+var minLinesFlag = cli.IntFlag{
+	Name:  "min-lines",
+	Usage: "Filter by at least this many lines",
+}
+
+var maxLinesFlag = cli.IntFlag{
+	Name:  "max-lines",
+	Usage: "Filter by at most this many lines",
+}
+
+var filesFlag = cli.StringFlag{
+	Name:  "files",
+	Usage: "Filter by number of files; a count (3), range (2-5), or minimum (2+)",
+}
+
+var minCommentsFlag = cli.IntFlag{
+	Name:  "min-comments",
+	Usage: "Filter by at least this many comments",
+}
+
+var minSizeFlag = cli.StringFlag{
+	Name:  "min-size",
+	Usage: "Filter by total size of at least; bytes (512) or a suffix (10k, 1mb)",
+}
+
+var maxSizeFlag = cli.StringFlag{
+	Name:  "max-size",
+	Usage: "Filter by total size of at most; bytes (512) or a suffix (10k, 1mb)",
+}
+
 var languageFlag = cli.StringFlag{
 	Name:  "language",
 	Value: "",
 	Usage: "Filter by language",
 }
 
-func main() {
+// Flags used by commands that edit tags
+var tagEditFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:    "dry-run",
+		Aliases: []string{"n"},
+		Usage:   "Preview changes without updating gists",
+	},
+	&cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "Update gists without confirming",
+	},
+}
+
+// Commands; Other arguments are passed to ls
+var queryReserve = []string{"sync", "set-editor", "logout",
+	"new", "edit", "note", "web", "w",
+	"open", "o", "rm", "ls", "list",
+	"search", "browse", "saved", "collection", "collections", "pin", "unpin", "related", "dupes", "recent", "last", "starred", "tag", "tags",
+	"language", "languages", "owner", "themes",
+	"help", "--help", "h", "-h",
+	"__run_alfred", "__record",
+	"debug"}
 
-	var queryReserve = []string{"sync", "set-editor", "logout",
-		"new", "edit", "web", "w",
-		"open", "o", "rm", "ls", "list",
-		"search", "starred", "tag", "tags",
-		"language", "languages", "owner",
-		"help", "--help", "h", "-h",
-		"__run_alfred",
-		"debug"}
+// Flags used by ls and saved searches
+var lsFlags = []cli.Flag{
+	&tagFlag,
+	&languageFlag,
+	&starredFlag,
+	&pinnedFlag,
+	&collectionFlag,
+	&statusFlag,
+	&sinceFlag,
+	&untilFlag,
+	&createdSinceFlag,
+	&createdUntilFlag,
+	&minLinesFlag,
+	&maxLinesFlag,
+	&filesFlag,
+	&minCommentsFlag,
+	&minSizeFlag,
+	&maxSizeFlag,
+	&sortFlag,
+	&limitFlag,
+	&pageFlag,
+	&offsetFlag,
+	&fuzzyFlag,
+	&facetsFlag,
+	&formatFlag,
+	&templateFlag,
+	&columnsFlag,
+	&styleFlag,
+	&formatterFlag,
+	&noPagerFlag,
+	&lineNumbersFlag,
+}
+
+// lsFlagsWithout - lsFlags except the named flags, so that
+// commands listing gists get new ls flags
+func lsFlagsWithout(names ...string) []cli.Flag {
+	var flags []cli.Flag
+	for _, flag := range lsFlags {
+		if contains(names, flag.Names()[0]) == false {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+func main() {
 	var searchTerm string
+	initColor()
 
 	app := cli.NewApp()
 
@@ -166,6 +377,20 @@ func main() {
 				return nil
 			},
 		},
+		{
+			// Records the use of a gist from Alfred actions
+			Name:      "__record",
+			Hidden:    true,
+			UsageText: "gg __record <open|copy|run> <ID>",
+			Action: func(c *cli.Context) error {
+				v, err := strconv.Atoi(c.Args().Get(1))
+				if err != nil {
+					ThrowError("Usage: gg __record <open|copy|run> <ID>", 1)
+				}
+				recordUsage(lookupGist(v), c.Args().First())
+				return nil
+			},
+		},
 		{
 			Name:                   "new",
 			Usage:                  "Create a new gist",
@@ -248,6 +473,31 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:      "note",
+			Usage:     "Edit a private note on a gist using $EDITOR",
+			UsageText: "\n\t\tgg note [--show] <ID>\n",
+			Category:  "Gists",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "show",
+					Usage: "Print the note",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				id, show := noteArgs(c.Args().Slice(), c.Bool("show"))
+				v, err := strconv.Atoi(id)
+				if err != nil {
+					ThrowError("Provide a gist ID", 1)
+				}
+				if show {
+					showNote(v)
+				} else {
+					editNote(v)
+				}
+				return nil
+			},
+		},
 		{
 			Name:      "sync",
 			Usage:     "Login and fetch your gist library",
@@ -302,6 +552,34 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:      "themes",
+			Usage:     "Preview syntax highlighting styles",
+			UsageText: "\n\t\tgg themes [options] [ID]\n\n\t\tID - Preview with a gist instead of a sample",
+			Category:  "Config",
+			Action: func(c *cli.Context) error {
+				if style := strings.ToLower(c.String("set")); style != "" {
+					validStyle(style)
+					config, _ := getConfig()
+					config.Style = style
+					saveConfig(config)
+					successMsg(fmt.Sprintf("Style set to %v\n", style))
+					return nil
+				}
+				setHighlight("", c.String("formatter"))
+				noPager = c.Bool("no-pager")
+				themes(c.Args().First())
+				return nil
+			},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "set",
+					Usage: "Save a style to the config",
+				},
+				&formatterFlag,
+				&noPagerFlag,
+			},
+		},
 		{
 			Name:      "logout",
 			Usage:     "Logout",
@@ -321,9 +599,19 @@ func main() {
 			Category:               "Query",
 			UseShortOptionHandling: true,
 			Action: func(c *cli.Context) error {
+				setOutputFormat(c.String("format"))
+				setOutputTemplate(c.String("template"))
+				setHighlight(c.String("style"), c.String("formatter"))
+				noPager = c.Bool("no-pager")
+				lineNumbers = c.Bool("line-numbers")
+				if isDataFormat() {
+					writeGists(idxGists(c.Args().Slice()))
+					return nil
+				}
 				if v, err := strconv.Atoi(c.Args().First()); err == nil {
 					if c.Bool("clipboard") {
 						clipboard.WriteAll(fetchGistContent(v))
+						recordUsage(lookupGist(v), "copy")
 						successMsg("Copied to clipboard")
 					} else {
 						for g := range c.Args().Slice() {
@@ -342,6 +630,12 @@ func main() {
 					Name:  "c, clipboard",
 					Usage: "Copy to clipboard. Only works for first gist.",
 				},
+				&formatFlag,
+				&templateFlag,
+				&styleFlag,
+				&formatterFlag,
+				&noPagerFlag,
+				&lineNumbersFlag,
 			},
 		},
 		{
@@ -361,6 +655,31 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:      "dupes",
+			Usage:     "Find duplicate gists",
+			UsageText: "\n\t\tgg dupes [options]\n\n\t\t",
+			Category:  "Query",
+			Action: func(c *cli.Context) error {
+				dupes(c.Float64("threshold"), c.Bool("diff"), c.Bool("rm"))
+				return nil
+			},
+			Flags: []cli.Flag{
+				&cli.Float64Flag{
+					Name:  "threshold",
+					Value: 0.8,
+					Usage: "Minimum similarity (0-1) of near duplicates",
+				},
+				&cli.BoolFlag{
+					Name:  "diff",
+					Usage: "Show a side-by-side diff of each copy with the original",
+				},
+				&cli.BoolFlag{
+					Name:  "rm",
+					Usage: "Prompt to remove each copy",
+				},
+			},
+		},
 		{
 			Name:                   "web",
 			Aliases:                []string{"w"},
@@ -387,7 +706,16 @@ func main() {
 			UseShortOptionHandling: true,
 			Action: func(c *cli.Context) error {
 				if v, err := strconv.Atoi(c.Args().Get(0)); err == nil {
-					outputGist(v)
+					setOutputFormat(c.String("format"))
+					setOutputTemplate(c.String("template"))
+					setHighlight(c.String("style"), c.String("formatter"))
+					noPager = c.Bool("no-pager")
+					lineNumbers = c.Bool("line-numbers")
+					if isDataFormat() {
+						writeGists(idxGists([]string{c.Args().Get(0)}))
+					} else {
+						outputGist(v)
+					}
 				} else {
 					// build search term
 					for i := 0; i <= c.NArg(); i++ {
@@ -399,26 +727,229 @@ func main() {
 				}
 				return nil
 			},
+			Flags: lsFlags,
+		},
+		{
+			Name:      "search",
+			Usage:     "Use fuzzy search to find Gist",
+			UsageText: "\n\t\tgg search [options] query\n",
+			Category:  "Query",
+			Action: func(c *cli.Context) error {
+				for i := 0; i <= c.NArg(); i++ {
+					searchTerm += " " + c.Args().Get(i)
+				}
+				squery.term = strings.Trim(searchTerm, " ")
+				fillQuery(&squery, c)
+				squery.fuzzy = "on"
+				ls(&squery)
+				return nil
+			},
+			Flags: lsFlagsWithout("fuzzy"),
+		},
+		{
+			Name:      "recent",
+			Usage:     "List recently used gists",
+			UsageText: "\n\t\tgg recent [options]\n\n\t\tUse --sort frecency to list gists used most often and most recently",
+			Category:  "Query",
+			Flags: append([]cli.Flag{
+				&cli.BoolFlag{
+					Name:  "clear",
+					Usage: "Clear recorded usage",
+				},
+			}, lsFlagsWithout("fuzzy", "facets")...),
+			Action: func(c *cli.Context) error {
+				if c.Bool("clear") {
+					clearUsage()
+					return nil
+				}
+				fillQuery(&squery, c)
+				recent(&squery)
+				return nil
+			},
+		},
+		{
+			Name:      "last",
+			Usage:     "List the last results shown in this terminal again",
+			UsageText: "\n\t\tgg last\n",
+			Category:  "Query",
+			Action: func(c *cli.Context) error {
+				showLastResults()
+				return nil
+			},
+		},
+		{
+			Name:      "related",
+			Usage:     "List gists on the same subject as a gist",
+			UsageText: "\n\t\tgg related [options] <ID>\n",
+			Category:  "Query",
+			Action: func(c *cli.Context) error {
+				v, err := strconv.Atoi(c.Args().First())
+				if err != nil {
+					ThrowError(fmt.Sprintf("%v is an invalid ID", c.Args().First()), 1)
+				}
+				fillQuery(&squery, c)
+				related(v, &squery)
+				return nil
+			},
 			Flags: []cli.Flag{
 				&tagFlag,
 				&languageFlag,
 				&starredFlag,
+				&pinnedFlag,
+				&collectionFlag,
 				&statusFlag,
-				&sortFlag,
 				&limitFlag,
+				&pageFlag,
+				&offsetFlag,
 			},
 		},
 		{
-			Name:      "search",
-			Usage:     "Use fuzzy search to find Gist",
-			UsageText: "\n\t\tgg search query\n",
+			Name:      "browse",
+			Usage:     "Browse and search gists interactively",
+			UsageText: "\n\t\tgg browse [options] [query]\n",
 			Category:  "Query",
 			Action: func(c *cli.Context) error {
-				for i := 0; i <= c.NArg(); i++ {
-					searchTerm += " " + c.Args().Get(i)
+				squery.term = strings.Join(c.Args().Slice(), " ")
+				fillQuery(&squery, c)
+				browse(&squery)
+				return nil
+			},
+			Flags: append(lsFlagsWithout("limit", "page", "offset", "facets", "format", "template",
+				"formatter", "no-pager", "line-numbers"),
+				&cli.IntFlag{
+					Name:    "limit",
+					Aliases: []string{"l"},
+					Value:   100,
+					Usage:   "Max number of results to display",
+				},
+			),
+		},
+		{
+			Name:      "saved",
+			Usage:     "Manage saved searches",
+			UsageText: "\n\t\tgg saved [add|list|rm]\n\n\t\tgg @name [options] [query] - run a saved search",
+			Category:  "Query",
+			Action: func(c *cli.Context) error {
+				savedSearchTable()
+				return nil
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:            "add",
+					Usage:           "Save a search",
+					UsageText:       "gg saved add <name> [options] [query]",
+					SkipFlagParsing: true,
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 2 {
+							ThrowError("Usage: gg saved add <name> [options] [query]", 1)
+						}
+						addSavedSearch(c.Args().First(), c.Args().Tail())
+						return nil
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List saved searches",
+					Action: func(c *cli.Context) error {
+						savedSearchTable()
+						return nil
+					},
+				},
+				{
+					Name:      "rm",
+					Usage:     "Remove a saved search",
+					UsageText: "gg saved rm <name>",
+					Action: func(c *cli.Context) error {
+						for _, name := range c.Args().Slice() {
+							rmSavedSearch(strings.TrimPrefix(name, "@"))
+						}
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:      "collection",
+			Aliases:   []string{"collections"},
+			Usage:     "Group gists in local collections",
+			UsageText: "\n\t\tgg collection [command]\n",
+			Category:  "Query",
+			Action: func(c *cli.Context) error {
+				collectionTable()
+				return nil
+			},
+			Subcommands: []*cli.Command{
+				{
+					Name:      "create",
+					Usage:     "Create a collection",
+					UsageText: "gg collection create <name>",
+					Action: func(c *cli.Context) error {
+						createCollection(c.Args().First())
+						return nil
+					},
+				},
+				{
+					Name:      "add",
+					Usage:     "Add gists to a collection",
+					UsageText: "gg collection add <name> <IDs...>",
+					Action: func(c *cli.Context) error {
+						addToCollection(c.Args().First(), c.Args().Tail())
+						return nil
+					},
+				},
+				{
+					Name:      "rm",
+					Usage:     "Remove gists from a collection, or the collection when no IDs are given",
+					UsageText: "gg collection rm <name> [IDs...]",
+					Action: func(c *cli.Context) error {
+						rmFromCollection(c.Args().First(), c.Args().Tail())
+						return nil
+					},
+				},
+				{
+					Name:      "ls",
+					Aliases:   []string{"list"},
+					Usage:     "List collections, or the gists in a collection",
+					UsageText: "gg collection ls [options] [name]",
+					Flags:     lsFlags,
+					Action: func(c *cli.Context) error {
+						if c.NArg() == 0 {
+							collectionTable()
+							return nil
+						}
+						fillQuery(&squery, c)
+						squery.collection = c.Args().First()
+						ls(&squery)
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:      "pin",
+			Usage:     "Pin gists to the top of the default listing",
+			UsageText: "\n\t\tgg pin [IDs...]\n\n\t\tLists pinned gists when no IDs are given",
+			Category:  "Query",
+			Flags:     lsFlags,
+			Action: func(c *cli.Context) error {
+				if c.NArg() == 0 {
+					fillQuery(&squery, c)
+					squery.pinned = true
+					ls(&squery)
+					return nil
 				}
-				searchTerm = strings.Trim(searchTerm, " ")
-				fuzzySearch(searchTerm)
+				pinGists(c.Args().Slice(), true)
+				return nil
+			},
+		},
+		{
+			Name:      "unpin",
+			Usage:     "Unpin gists",
+			UsageText: "\n\t\tgg unpin <IDs...>\n",
+			Category:  "Query",
+			Action: func(c *cli.Context) error {
+				pinGists(c.Args().Slice(), false)
 				return nil
 			},
 		},
@@ -427,15 +958,13 @@ func main() {
 			Usage:     "List and query starred",
 			UsageText: "\n\t\tgg starred [query]\n",
 			Category:  "Query",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{
 					Name:  "query",
 					Value: "",
 					Usage: "Filter by starred gists",
 				},
-				&statusFlag,
-				&limitFlag,
-			},
+			}, lsFlagsWithout("starred")...),
 			Action: func(c *cli.Context) error {
 				if len(c.Args().Slice()) > 0 {
 					searchTerm = strings.Join(c.Args().Slice(), " ")
@@ -453,18 +982,77 @@ func main() {
 			Usage:     "List and query tag",
 			UsageText: "\n\t\tgg tag [tag name] [query]\n",
 			Category:  "Query",
-			Flags: []cli.Flag{
+			Subcommands: []*cli.Command{
+				{
+					Name:      "add",
+					Usage:     "Add a tag to gists",
+					UsageText: "gg tag add [options] <IDs...> <tag>",
+					Flags:     tagEditFlags,
+					Action: func(c *cli.Context) error {
+						addTags(c.Args().Slice(), c.Bool("dry-run"), c.Bool("yes"))
+						return nil
+					},
+				},
+				{
+					Name:      "rm",
+					Usage:     "Remove a tag from gists",
+					UsageText: "gg tag rm [options] <IDs...> <tag>",
+					Flags:     tagEditFlags,
+					Action: func(c *cli.Context) error {
+						rmTags(c.Args().Slice(), c.Bool("dry-run"), c.Bool("yes"))
+						return nil
+					},
+				},
+				{
+					Name:      "rename",
+					Usage:     "Rename a tag and its children on all of your gists",
+					UsageText: "gg tag rename [options] <tag> <new tag>",
+					Flags:     tagEditFlags,
+					Action: func(c *cli.Context) error {
+						if c.Args().Len() != 2 {
+							ThrowError("Provide a tag and its new name", 1)
+						}
+						mergeTags(c.Args().Slice(), c.Bool("dry-run"), c.Bool("yes"))
+						return nil
+					},
+				},
+				{
+					Name:      "apply-rules",
+					Usage:     "Update tags derived by the tag rules in your config",
+					UsageText: "gg tag apply-rules [options]",
+					Flags: append([]cli.Flag{
+						&cli.BoolFlag{
+							Name:  "push",
+							Usage: "Add derived tags to the descriptions of your gists",
+						},
+					}, tagEditFlags...),
+					Action: func(c *cli.Context) error {
+						applyRules(c.Bool("push"), c.Bool("dry-run"), c.Bool("yes"))
+						return nil
+					},
+				},
+				{
+					Name:      "merge",
+					Usage:     "Merge tags into a single tag on all of your gists",
+					UsageText: "gg tag merge [options] <tags...> <new tag>",
+					Flags:     tagEditFlags,
+					Action: func(c *cli.Context) error {
+						mergeTags(c.Args().Slice(), c.Bool("dry-run"), c.Bool("yes"))
+						return nil
+					},
+				},
+			},
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{
 					Name:  "query",
 					Value: "",
 					Usage: "Filter by tag (omit the # prefix)",
 				},
-				&statusFlag,
-				&limitFlag,
-			},
+			}, lsFlagsWithout("tag")...),
 			Action: func(c *cli.Context) error {
 				if c.Args().First() == "" {
-					fieldSummary("Tags")
+					fillQuery(&squery, c)
+					fieldSummary("Tags", &squery)
 				} else {
 					if len(c.Args().Slice()) > 1 {
 						searchTerm = strings.Join(c.Args().Slice()[1:len(c.Args().Slice())], " ")
@@ -483,18 +1071,17 @@ func main() {
 			Usage:     "List and query language",
 			UsageText: "\n\t\tgg language [language-name] [query]\n",
 			Category:  "Query",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{
 					Name:  "query",
 					Value: "",
 					Usage: "Filter by language",
 				},
-				&statusFlag,
-				&limitFlag,
-			},
+			}, lsFlagsWithout("language")...),
 			Action: func(c *cli.Context) error {
 				if c.Args().First() == "" {
-					fieldSummary("Language")
+					fillQuery(&squery, c)
+					fieldSummary("Language", &squery)
 				} else {
 					if len(c.Args().Slice()) > 1 {
 						searchTerm = strings.Join(c.Args().Slice()[1:len(c.Args().Slice())], " ")
@@ -512,18 +1099,17 @@ func main() {
 			Usage:     "List and query owner",
 			UsageText: "\n\t\tgg owner [owner] [query]\n",
 			Category:  "Query",
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{
 					Name:  "owner",
 					Value: "",
 					Usage: "Filter by owner",
 				},
-				&statusFlag,
-				&limitFlag,
-			},
+			}, lsFlags...),
 			Action: func(c *cli.Context) error {
 				if c.Args().First() == "" {
-					fieldSummary("Owner")
+					fillQuery(&squery, c)
+					fieldSummary("Owner", &squery)
 				} else {
 					if len(c.Args().Slice()) > 1 {
 						searchTerm = strings.Join(c.Args().Slice()[1:len(c.Args().Slice())], " ")
@@ -539,12 +1125,19 @@ func main() {
 	}
 
 	var a string
+	// Resolve positional references (@1) to gist IDs
+	if len(os.Args) > 1 && os.Args[1] != "__run_alfred" {
+		os.Args = resolvePositions(os.Args)
+		commandArgs = os.Args
+	}
 	if len(os.Args) > 1 {
 		a = os.Args[1]
 	}
 	args := os.Args
 	if _, err := strconv.Atoi(a); err == nil {
 		args = insert(args, 1, "o")
+	} else if strings.HasPrefix(a, "@") {
+		args = append([]string{args[0], "ls"}, savedSearchArgs(a[1:], args[2:])...)
 	} else if contains(queryReserve, a) == false {
 		args = insert(args, 1, "ls")
 	} else {
@@ -12,15 +12,16 @@ import (
 )
 
 var (
-	newIcon        = &aw.Icon{"icons/new.png", aw.IconTypeImage}
-	tagIcon        = &aw.Icon{"icons/tag.png", aw.IconTypeImage}
-	collectionIcon = &aw.Icon{"icons/collection.png", aw.IconTypeImage}
-	languageIcon   = &aw.Icon{"icons/language.png", aw.IconTypeImage}
-	tokenIcon      = &aw.Icon{"icons/token.png", aw.IconTypeImage}
-	starIcon       = &aw.Icon{"icons/star.png", aw.IconTypeImage}
-	forkIcon       = &aw.Icon{"icons/forked.png", aw.IconTypeImage}
-	latestIcon     = &aw.Icon{"icons/latest.png", aw.IconTypeImage}
-	iconAvailable  = &aw.Icon{"icons/update-available.png", aw.IconTypeImage}
+	newIcon        = &aw.Icon{Value: "icons/new.png", Type: aw.IconTypeImage}
+	tagIcon        = &aw.Icon{Value: "icons/tag.png", Type: aw.IconTypeImage}
+	collectionIcon = &aw.Icon{Value: "icons/collection.png", Type: aw.IconTypeImage}
+	savedIcon      = &aw.Icon{Value: "icons/collection2.png", Type: aw.IconTypeImage}
+	languageIcon   = &aw.Icon{Value: "icons/language.png", Type: aw.IconTypeImage}
+	tokenIcon      = &aw.Icon{Value: "icons/token.png", Type: aw.IconTypeImage}
+	starIcon       = &aw.Icon{Value: "icons/star.png", Type: aw.IconTypeImage}
+	forkIcon       = &aw.Icon{Value: "icons/forked.png", Type: aw.IconTypeImage}
+	latestIcon     = &aw.Icon{Value: "icons/latest.png", Type: aw.IconTypeImage}
+	iconAvailable  = &aw.Icon{Value: "icons/update-available.png", Type: aw.IconTypeImage}
 )
 
 func randomOwnerIcon() *aw.Icon {
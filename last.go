@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/query"
+)
+
+// The last results listed in each terminal are stored so that
+// they can be referred to by position (@1) or listed again.
+var libLastPath = fmt.Sprintf("%s/last", getLibraryDirectory())
+
+var positionRef = regexp.MustCompile(`^@(\d+)$`)
+
+// Results of terminals unused for this long are removed
+const lastResultsAge = 7 * 24 * time.Hour
+
+// lastHit - a listed gist; the GistID detects IDs that
+// changed since the results were listed
+type lastHit struct {
+	IDX    int     `json:"idx"`
+	GistID string  `json:"gist_id"`
+	Score  float64 `json:"score"`
+}
+
+type lastResults struct {
+	Args    []string  `json:"args"`
+	IsQuery bool      `json:"is_query"`
+	Total   uint64    `json:"total"`
+	From    int       `json:"from"`
+	Offset  int       `json:"offset"`
+	Size    int       `json:"size"`
+	Hits    []lastHit `json:"hits"`
+}
+
+// terminalName - identifies the terminal so that parallel shells
+// keep separate results. The tty is read from /proc where available
+// and from tty(1) otherwise (macOS); the parent process (shell) is
+// used when stdin is not a terminal.
+func terminalName() string {
+	name, err := os.Readlink("/proc/self/fd/0")
+	if err != nil || strings.HasPrefix(name, "/dev/") == false {
+		cmd := exec.Command("tty")
+		cmd.Stdin = os.Stdin
+		out, _ := cmd.Output()
+		name = strings.TrimSpace(string(out))
+	}
+	if strings.HasPrefix(name, "/dev/") == false || name == "/dev/null" {
+		return fmt.Sprintf("ppid-%v", os.Getppid())
+	}
+	return strings.Replace(strings.TrimPrefix(name, "/dev/"), "/", "-", -1)
+}
+
+func lastResultsPath() string {
+	return fmt.Sprintf("%s/%s.json", libLastPath, terminalName())
+}
+
+// saveLastResults - stores the results listed in this terminal
+func saveLastResults(results *bleve.SearchResult, isQuery bool, offset int) {
+	last := lastResults{
+		Args:    commandArgs,
+		IsQuery: isQuery,
+		Offset:  offset,
+		Total:   results.Total,
+		Size:    len(results.Hits),
+	}
+	for _, gist := range results.Hits {
+		last.Hits = append(last.Hits, lastHit{
+			IDX:    int(fieldFloat(gist, "IDX")),
+			GistID: fieldString(gist, "GistID"),
+			Score:  gist.Score,
+		})
+	}
+	if results.Request != nil {
+		last.From = results.Request.From
+		last.Size = results.Request.Size
+	}
+
+	_ = os.MkdirAll(libLastPath, 0755)
+	pruneLastResults()
+	out, err := json.Marshal(last)
+	check(err)
+	err = ioutil.WriteFile(lastResultsPath(), out, 0644)
+	check(err)
+}
+
+// pruneLastResults - removes the results of terminals
+// that have not listed gists recently
+func pruneLastResults() {
+	files, _ := filepath.Glob(filepath.Join(libLastPath, "*.json"))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil && time.Since(info.ModTime()) > lastResultsAge {
+			os.Remove(file)
+		}
+	}
+}
+
+func loadLastResults() (lastResults, error) {
+	var last lastResults
+	data, err := ioutil.ReadFile(lastResultsPath())
+	if err != nil {
+		return last, err
+	}
+	err = json.Unmarshal(data, &last)
+	return last, err
+}
+
+// lastHitGist - the gist of a listed result, or nil when
+// its ID now belongs to another gist (e.g. after a rebuild)
+func lastHitGist(hit lastHit) *search.DocumentMatch {
+	q := query.NewQueryStringQuery(fmt.Sprintf("IDX:%v", hit.IDX))
+	sr := bleve.NewSearchRequest(q)
+	sr.Fields = []string{"*"}
+	results, err := dbIdx.Search(sr)
+	if err != nil || len(results.Hits) == 0 || fieldString(results.Hits[0], "GistID") != hit.GistID {
+		return nil
+	}
+	gist := results.Hits[0]
+	gist.Score = hit.Score
+	return gist
+}
+
+// positionHit - the hit that a position (@N) refers to
+func positionHit(arg string, hits []lastHit) (lastHit, error) {
+	pos, _ := strconv.Atoi(strings.TrimPrefix(arg, "@"))
+	if pos < 1 || pos > len(hits) {
+		return lastHit{}, fmt.Errorf("%s is out of range; the last results have %v gists", arg, len(hits))
+	}
+	return hits[pos-1], nil
+}
+
+// resolvePositions - replaces @N arguments with the ID of the
+// Nth gist of the last results listed in this terminal
+func resolvePositions(args []string) []string {
+	var last *lastResults
+	resolved := make([]string, len(args))
+	for i, arg := range args {
+		if positionRef.MatchString(arg) == false {
+			resolved[i] = arg
+			continue
+		}
+		if last == nil {
+			l, err := loadLastResults()
+			if err != nil {
+				ThrowError("No results to refer to; list gists with 'gg ls' first", 1)
+			}
+			last = &l
+		}
+		hit, err := positionHit(arg, last.Hits)
+		if err != nil {
+			ThrowError(err.Error(), 1)
+		}
+		if lastHitGist(hit) == nil {
+			ThrowError(fmt.Sprintf("%s no longer refers to the same gist; list gists again", arg), 1)
+		}
+		resolved[i] = strconv.Itoa(hit.IDX)
+	}
+	return resolved
+}
+
+// showLastResults - lists the last results again without searching
+func showLastResults() {
+	last, err := loadLastResults()
+	if err != nil || len(last.Hits) == 0 {
+		errorMsg("No Results\n")
+		os.Exit(0)
+	}
+	// Gists changed since they were listed are skipped
+	var hits search.DocumentMatchCollection
+	for _, hit := range last.Hits {
+		if gist := lastHitGist(hit); gist != nil {
+			hits = append(hits, gist)
+		}
+	}
+	if len(hits) == 0 {
+		errorMsg("No Results\n")
+		os.Exit(0)
+	}
+	results := &bleve.SearchResult{
+		Hits:  hits,
+		Total: last.Total,
+		Request: &bleve.SearchRequest{
+			From: last.From,
+			Size: last.Size,
+		},
+	}
+	// Suggest the next page of the original command
+	commandArgs = last.Args
+	resultTable(results, last.IsQuery, last.Offset)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPositionHit(t *testing.T) {
+	hits := []lastHit{
+		{IDX: 12, GistID: "a"},
+		{IDX: 3, GistID: "b"},
+		{IDX: 7, GistID: "c"},
+	}
+	tests := []struct {
+		arg     string
+		wantIDX int
+		wantErr bool
+	}{
+		{"@1", 12, false},
+		{"@2", 3, false},
+		{"@3", 7, false},
+		{"@0", 0, true},
+		{"@4", 0, true},
+	}
+	for _, tt := range tests {
+		hit, err := positionHit(tt.arg, hits)
+		if (err != nil) != tt.wantErr || hit.IDX != tt.wantIDX {
+			t.Errorf("positionHit(%q) = %v, %v; want %v (error: %v)", tt.arg, hit.IDX, err, tt.wantIDX, tt.wantErr)
+		}
+	}
+}
+
+func TestResolvePositionsWithoutPositions(t *testing.T) {
+	// Arguments that are not positions are kept as they are
+	args := []string{"gg", "ls", "@name", "a@1", "@1x", "--tag", "aws"}
+	if got := resolvePositions(args); !reflect.DeepEqual(got, args) {
+		t.Errorf("resolvePositions(%q) = %q", args, got)
+	}
+}
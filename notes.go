@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blevesearch/bleve/search"
+)
+
+// Notes are stored as files named by GistID so that they persist
+// across syncs and rebuilds. They are never sent to GitHub.
+var libNotesPath = fmt.Sprintf("%s/notes", getLibraryDirectory())
+
+func notePath(gistID string) string {
+	return fmt.Sprintf("%s/%s.md", libNotesPath, gistID)
+}
+
+func loadNote(gistID string) string {
+	data, err := ioutil.ReadFile(notePath(gistID))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// loadNotes - returns all notes by GistID
+func loadNotes() map[string]string {
+	notes := map[string]string{}
+	files, _ := filepath.Glob(filepath.Join(libNotesPath, "*.md"))
+	for _, file := range files {
+		gistID := strings.TrimSuffix(filepath.Base(file), ".md")
+		notes[gistID] = loadNote(gistID)
+	}
+	return notes
+}
+
+// writeNote - saves a note; an empty note is removed
+func writeNote(gistID string, note string) {
+	if note == "" {
+		os.Remove(notePath(gistID))
+		return
+	}
+	_ = os.MkdirAll(libNotesPath, 0755)
+	err := ioutil.WriteFile(notePath(gistID), []byte(note+"\n"), 0644)
+	check(err)
+}
+
+// noteArgs - returns the gist ID and whether to print the note.
+// Flags after the ID are not parsed, so a trailing --show is
+// handled here.
+func noteArgs(args []string, show bool) (string, bool) {
+	if len(args) == 0 {
+		return "", show
+	}
+	for _, arg := range args[1:] {
+		if contains([]string{"--show", "-show"}, arg) {
+			show = true
+		}
+	}
+	return args[0], show
+}
+
+// editNote - edits the note of a gist and updates its index record
+func editNote(gistIdx int) {
+	gist := lookupGist(gistIdx)
+	gistID := fmt.Sprintf("%v", gist.Fields["GistID"])
+	note := loadNote(gistID)
+
+	tmpfile, err := ioutil.TempFile("", "note.*.md")
+	check(err)
+	defer os.Remove(tmpfile.Name())
+	tmpfile.WriteString(note)
+	tmpfile.Close()
+
+	cmd := editorCommand(tmpfile.Name())
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		ThrowError(fmt.Sprintf("Error running editor: %s", err), 1)
+	}
+	edit, err := ioutil.ReadFile(tmpfile.Name())
+	if err != nil {
+		ThrowError("Error reading note", 1)
+	}
+
+	updated := strings.TrimSpace(string(edit))
+	if updated == note {
+		boldMsg("Note unchanged\n")
+		return
+	}
+	writeNote(gistID, updated)
+	indexNote(gist, updated)
+	if updated == "" {
+		successMsg(fmt.Sprintf("Removed note from %v\n", gistIdx))
+	} else {
+		successMsg(fmt.Sprintf("Saved note for %v\n", gistIdx))
+	}
+}
+
+func indexNote(gist *search.DocumentMatch, note string) {
+	sn := snippetFromDoc(gist)
+	sn.Note = note
+	err := dbIdx.Index(sn.ID, sn)
+	check(err)
+}
+
+func showNote(gistIdx int) {
+	gist := lookupGist(gistIdx)
+	note := loadNote(fmt.Sprintf("%v", gist.Fields["GistID"]))
+	if note == "" {
+		errorMsg(fmt.Sprintf("%v has no note\n", gistIdx))
+		os.Exit(1)
+	}
+	fmt.Println(note)
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestNoteArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		showFlag bool
+		wantID   string
+		wantShow bool
+	}{
+		{[]string{"12"}, false, "12", false},
+		// gg note --show 12
+		{[]string{"12"}, true, "12", true},
+		// gg note 12 --show
+		{[]string{"12", "--show"}, false, "12", true},
+		{[]string{"12", "-show"}, false, "12", true},
+		{[]string{}, false, "", false},
+	}
+	for _, tt := range tests {
+		id, show := noteArgs(tt.args, tt.showFlag)
+		if id != tt.wantID || show != tt.wantShow {
+			t.Errorf("noteArgs(%q, %v) = %q, %v; want %q, %v", tt.args, tt.showFlag, id, show, tt.wantID, tt.wantShow)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blevesearch/bleve/search/query"
+)
+
+// Counts; e.g. 3, 2-5, 2+
+var countRange = regexp.MustCompile(`^(\d+)(?:(\+)|-(\d+))?$`)
+
+// Byte sizes; e.g. 512, 10k, 1.5mb
+var byteSize = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kmg]?)b?$`)
+
+// parseCountRange - parses an exact count (3), a range (2-5),
+// or a minimum (2+). A nil bound is unbounded.
+func parseCountRange(s string) (*float64, *float64, error) {
+	m := countRange.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return nil, nil, errors.New("Unable to parse")
+	}
+	min, _ := strconv.ParseFloat(m[1], 64)
+	switch {
+	case m[2] == "+":
+		return &min, nil, nil
+	case m[3] != "":
+		max, _ := strconv.ParseFloat(m[3], 64)
+		return &min, &max, nil
+	}
+	return &min, &min, nil
+}
+
+// parseByteSize - parses a size in bytes with an optional
+// k, m, or g suffix.
+func parseByteSize(s string) (float64, error) {
+	m := byteSize.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, errors.New("Unable to parse")
+	}
+	size, _ := strconv.ParseFloat(m[1], 64)
+	switch m[2] {
+	case "k":
+		size *= 1024
+	case "m":
+		size *= 1024 * 1024
+	case "g":
+		size *= 1024 * 1024 * 1024
+	}
+	return size, nil
+}
+
+// formatByteSize - formats a size in bytes with a k, m, or g suffix
+func formatByteSize(size float64) string {
+	for _, unit := range []string{"", "k", "m"} {
+		if size < 1024 {
+			return strings.TrimSuffix(fmt.Sprintf("%.1f", size), ".0") + unit
+		}
+		size /= 1024
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", size), ".0") + "g"
+}
+
+// numericRangeQuery - filters field on an inclusive range.
+// Returns nil when neither bound is set.
+func numericRangeQuery(field string, min *float64, max *float64) query.Query {
+	if min == nil && max == nil {
+		return nil
+	}
+	inclusive := true
+	q := query.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+	q.SetField(field)
+	return q
+}
+
+// Returns a pointer to n when it is set (> 0)
+func minMaxInt(n int) *float64 {
+	if n <= 0 {
+		return nil
+	}
+	f := float64(n)
+	return &f
+}
+
+// Returns a pointer to the parsed size when it is set
+func minMaxSize(s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	size, err := parseByteSize(s)
+	if err != nil {
+		ThrowError(fmt.Sprintf("Invalid size '%s'; Use bytes (512) or a suffix (10k, 1mb)", s), 1)
+	}
+	return &size
+}
+
+// numericFilters - filters on line count, file count, comments, and size
+func numericFilters(search *searchQuery) []query.Query {
+	var filesMin, filesMax *float64
+	if search.files != "" {
+		var err error
+		if filesMin, filesMax, err = parseCountRange(search.files); err != nil {
+			ThrowError(fmt.Sprintf("Invalid file count '%s'; Use a count (3), range (2-5), or minimum (2+)", search.files), 1)
+		}
+	}
+	var filters []query.Query
+	for _, q := range []query.Query{
+		numericRangeQuery("NLines", minMaxInt(search.minLines), minMaxInt(search.maxLines)),
+		numericRangeQuery("NFiles", filesMin, filesMax),
+		numericRangeQuery("Comments", minMaxInt(search.minComments), nil),
+		numericRangeQuery("Size", minMaxSize(search.minSize), minMaxSize(search.maxSize)),
+	} {
+		if q != nil {
+			filters = append(filters, q)
+		}
+	}
+	return filters
+}
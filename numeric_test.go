@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestParseCountRange(t *testing.T) {
+	tests := []struct {
+		s       string
+		wantMin float64
+		wantMax float64
+		noMax   bool
+	}{
+		{"3", 3, 3, false},
+		{"2-5", 2, 5, false},
+		{" 2+ ", 2, 0, true},
+	}
+	for _, tt := range tests {
+		min, max, err := parseCountRange(tt.s)
+		if err != nil {
+			t.Errorf("parseCountRange(%q): %s", tt.s, err)
+			continue
+		}
+		if *min != tt.wantMin || (max == nil) != tt.noMax || (max != nil && *max != tt.wantMax) {
+			t.Errorf("parseCountRange(%q) = %v, %v; want %v, %v", tt.s, *min, max, tt.wantMin, tt.wantMax)
+		}
+	}
+
+	for _, s := range []string{"", "a", "-2", "2-", "1.5"} {
+		if _, _, err := parseCountRange(s); err == nil {
+			t.Errorf("parseCountRange(%q) should fail", s)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{"512", 512},
+		{"512b", 512},
+		{"10k", 10 * 1024},
+		{"10KB", 10 * 1024},
+		{"1.5mb", 1.5 * 1024 * 1024},
+		{"2 g", 2 * 1024 * 1024 * 1024},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("parseByteSize(%q) = %v, %v; want %v", tt.s, got, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "k", "10t", "-1k"} {
+		if _, err := parseByteSize(s); err == nil {
+			t.Errorf("parseByteSize(%q) should fail", s)
+		}
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	tests := []struct {
+		size float64
+		want string
+	}{
+		{0, "0"},
+		{512, "512"},
+		{1024, "1k"},
+		{1536, "1.5k"},
+		{5 * 1024 * 1024, "5m"},
+		{3 * 1024 * 1024 * 1024, "3g"},
+	}
+	for _, tt := range tests {
+		if got := formatByteSize(tt.size); got != tt.want {
+			t.Errorf("formatByteSize(%v) = %q; want %q", tt.size, got, tt.want)
+		}
+	}
+}
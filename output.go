@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v2"
+)
+
+// Formats for scripting; alfred is set by the workflow
+var outputFormats = []string{"console", "json", "jsonl", "csv", "tsv", "yaml"}
+
+// setOutputFormat - applies --format; colors are disabled
+// for formats other than console
+func setOutputFormat(format string) {
+	format = strings.ToLower(format)
+	if format == "" || outputFormat == "alfred" {
+		return
+	}
+	if contains(outputFormats, format) == false {
+		ThrowError(fmt.Sprintf("--format must be one of: %s", strings.Join(outputFormats, ", ")), 1)
+	}
+	outputFormat = format
+	if format != "console" {
+		color.NoColor = true
+	}
+}
+
+// isDataFormat - true when output is for scripts
+func isDataFormat() bool {
+	return outputFormat != "console" && outputFormat != "alfred"
+}
+
+type fileRecord struct {
+	Filename string `json:"Filename" yaml:"Filename"`
+	Language string `json:"Language" yaml:"Language"`
+	Content  string `json:"Content" yaml:"Content"`
+}
+
+// gistRecord - a gist as output for scripts; field names follow Snippet
+type gistRecord struct {
+	IDX         int          `json:"IDX" yaml:"IDX"`
+	GistID      string       `json:"GistID" yaml:"GistID"`
+	Owner       string       `json:"Owner" yaml:"Owner"`
+	Description string       `json:"Description" yaml:"Description"`
+	Public      bool         `json:"Public" yaml:"Public"`
+	Starred     bool         `json:"Starred" yaml:"Starred"`
+	Pinned      bool         `json:"Pinned" yaml:"Pinned"`
+	Filename    []string     `json:"Filename" yaml:"Filename"`
+	Language    []string     `json:"Language" yaml:"Language"`
+	Tags        []string     `json:"Tags" yaml:"Tags"`
+	RuleTags    []string     `json:"RuleTags" yaml:"RuleTags"`
+	Collections []string     `json:"Collections" yaml:"Collections"`
+	Note        string       `json:"Note" yaml:"Note"`
+	NFiles      int          `json:"NFiles" yaml:"NFiles"`
+	NLines      int          `json:"NLines" yaml:"NLines"`
+	Size        int          `json:"Size" yaml:"Size"`
+	Comments    int          `json:"Comments" yaml:"Comments"`
+	CreatedAt   time.Time    `json:"CreatedAt" yaml:"CreatedAt"`
+	UpdatedAt   time.Time    `json:"UpdatedAt" yaml:"UpdatedAt"`
+	URL         string       `json:"URL" yaml:"URL"`
+	Score       float64      `json:"Score" yaml:"Score"`
+	Files       []fileRecord `json:"Files,omitempty" yaml:"Files,omitempty"`
+}
+
+// resultsRecord - a page of results with the total number of results
+type resultsRecord struct {
+	Total  uint64       `json:"Total" yaml:"Total"`
+	From   int          `json:"From" yaml:"From"`
+	Hits   []gistRecord `json:"Hits" yaml:"Hits"`
+	Facets []facetGroup `json:"Facets,omitempty" yaml:"Facets,omitempty"`
+}
+
+// summaryRecord - counts for gg tag, gg language, and gg owner
+type summaryRecord struct {
+	Field  string       `json:"Field" yaml:"Field"`
+	Counts []facetCount `json:"Counts" yaml:"Counts"`
+}
+
+// Columns for csv and tsv
+var recordColumns = []string{"IDX", "GistID", "Owner", "Description", "Public", "Starred", "Pinned",
+	"Filename", "Language", "Tags", "RuleTags", "Collections", "Note", "NFiles", "NLines", "Size",
+	"Comments", "CreatedAt", "UpdatedAt", "URL", "Score"}
+
+var fileColumns = []string{"IDX", "GistID", "Description", "Filename", "Language", "Content"}
+
+func newGistRecord(gist *search.DocumentMatch, withFiles bool) gistRecord {
+	sn := snippetFromDoc(gist)
+	record := gistRecord{
+		IDX:         sn.IDX,
+		GistID:      sn.GistID,
+		Owner:       sn.Owner,
+		Description: sn.Description,
+		Public:      sn.Public == "T",
+		Starred:     sn.Starred == "T",
+		Pinned:      sn.Pinned == "T",
+		Filename:    nonNil(sn.Filename),
+		Language:    nonNil(sn.Language),
+		Tags:        nonNil(sn.Tags),
+		RuleTags:    nonNil(sn.RuleTags),
+		Collections: nonNil(sn.Collections),
+		Note:        sn.Note,
+		NFiles:      sn.NFiles,
+		NLines:      sn.NLines,
+		Size:        sn.Size,
+		Comments:    sn.Comments,
+		CreatedAt:   sn.CreatedAt,
+		UpdatedAt:   sn.UpdatedAt,
+		URL:         sn.URL,
+		Score:       gist.Score,
+	}
+	if withFiles {
+		fileset := parseGistFiles(gist)
+		filenames := make([]string, 0, len(fileset))
+		for filename := range fileset {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+		for _, filename := range filenames {
+			file := fileset[filename]
+			record.Files = append(record.Files, fileRecord{file["filename"], file["language"], file["content"]})
+		}
+	}
+	return record
+}
+
+// nonNil - lists are output as [] rather than null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func (r gistRecord) row() []string {
+	return []string{
+		fmt.Sprintf("%v", r.IDX), r.GistID, r.Owner, r.Description,
+		fmt.Sprintf("%v", r.Public), fmt.Sprintf("%v", r.Starred), fmt.Sprintf("%v", r.Pinned),
+		strings.Join(r.Filename, ","), strings.Join(r.Language, ","), strings.Join(r.Tags, ","),
+		strings.Join(r.RuleTags, ","), strings.Join(r.Collections, ","), r.Note,
+		fmt.Sprintf("%v", r.NFiles), fmt.Sprintf("%v", r.NLines), fmt.Sprintf("%v", r.Size),
+		fmt.Sprintf("%v", r.Comments), r.CreatedAt.Format(time.RFC3339), r.UpdatedAt.Format(time.RFC3339),
+		r.URL, fmt.Sprintf("%v", r.Score),
+	}
+}
+
+func writeJSON(v interface{}) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	check(err)
+	fmt.Println(string(out))
+}
+
+func writeYAML(v interface{}) {
+	out, err := yaml.Marshal(v)
+	check(err)
+	fmt.Print(string(out))
+}
+
+func writeJSONLines(items []interface{}) {
+	for _, item := range items {
+		out, err := json.Marshal(item)
+		check(err)
+		fmt.Println(string(out))
+	}
+}
+
+func writeRows(header []string, rows [][]string) {
+	w := csv.NewWriter(os.Stdout)
+	if outputFormat == "tsv" {
+		w.Comma = '\t'
+	}
+	w.Write(header)
+	w.WriteAll(rows)
+	check(w.Error())
+}
+
+// writeRecords - outputs gists in the data format; totals and
+// facets are only included in json and yaml
+func writeRecords(output resultsRecord) {
+	switch outputFormat {
+	case "json":
+		writeJSON(output)
+	case "yaml":
+		writeYAML(output)
+	case "template":
+		writeTemplate(output.Hits)
+	case "jsonl":
+		items := make([]interface{}, len(output.Hits))
+		for i, record := range output.Hits {
+			items[i] = record
+		}
+		writeJSONLines(items)
+	case "csv", "tsv":
+		// Gists with files have a row for each file
+		columns := recordColumns
+		for _, record := range output.Hits {
+			if record.Files != nil {
+				columns = fileColumns
+			}
+		}
+		var rows [][]string
+		for _, record := range output.Hits {
+			if record.Files == nil {
+				rows = append(rows, record.row())
+			}
+			for _, file := range record.Files {
+				rows = append(rows, []string{fmt.Sprintf("%v", record.IDX), record.GistID, record.Description,
+					file.Filename, file.Language, file.Content})
+			}
+		}
+		writeRows(columns, rows)
+	}
+}
+
+// writeResults - outputs search results in the data format
+func writeResults(results *bleve.SearchResult, search *searchQuery) {
+	output := resultsRecord{Total: results.Total, Hits: []gistRecord{}}
+	if results.Request != nil {
+		output.From = results.Request.From
+	}
+	for _, gist := range results.Hits {
+		output.Hits = append(output.Hits, newGistRecord(gist, false))
+	}
+	if search.facets {
+		output.Facets = resultFacets(results)
+	}
+	writeRecords(output)
+}
+
+// writeGists - outputs gists with their files in the data format
+func writeGists(gists []*search.DocumentMatch) {
+	output := resultsRecord{Total: uint64(len(gists)), Hits: []gistRecord{}}
+	for _, gist := range gists {
+		recordUsage(gist, "open")
+		output.Hits = append(output.Hits, newGistRecord(gist, true))
+	}
+	writeRecords(output)
+}
+
+// writeSummary - outputs field counts in the data format
+func writeSummary(field string, data [][]string) {
+	summary := summaryRecord{Field: field, Counts: []facetCount{}}
+	for _, row := range data {
+		var count int
+		fmt.Sscan(row[1], &count)
+		summary.Counts = append(summary.Counts, facetCount{row[0], count})
+	}
+	switch outputFormat {
+	case "json":
+		writeJSON(summary)
+	case "yaml":
+		writeYAML(summary)
+	case "jsonl":
+		items := make([]interface{}, len(summary.Counts))
+		for i, count := range summary.Counts {
+			items[i] = count
+		}
+		writeJSONLines(items)
+	case "csv", "tsv":
+		writeRows([]string{field, "Count"}, data)
+	case "template":
+		for _, count := range summary.Counts {
+			writeTemplateItem(count)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/formatters"
+	"github.com/alecthomas/chroma/styles"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Set by --no-pager
+var noPager = false
+
+// Set by -n/--line-numbers
+var lineNumbers = false
+
+// pagerCommand - $PAGER or less; $PAGER may include arguments
+func pagerCommand() *exec.Cmd {
+	args := strings.Fields(os.Getenv("PAGER"))
+	if len(args) == 0 {
+		args = []string{"less", "-R"}
+	}
+	return exec.Command(args[0], args[1:]...)
+}
+
+// page - outputs through the pager when output is
+// longer than the terminal
+func page(output string) {
+	_, ysize, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if noPager || err != nil || outputPipe() || strings.Count(output, "\n") < ysize {
+		fmt.Print(output)
+		return
+	}
+	cmd := pagerCommand()
+	cmd.Stdin = strings.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		// The pager is not installed
+		fmt.Print(output)
+		return
+	}
+	cmd.Wait()
+}
+
+// highlightNumbered - highlights code with line numbers
+func highlightNumbered(out io.Writer, filename string, content string, formatter string, style string) {
+	lexer := chroma.Coalesce(fileLexer(filename, content))
+	iterator, err := lexer.Tokenise(nil, content)
+	if err != nil {
+		fmt.Fprint(out, content)
+		return
+	}
+	lines := chroma.SplitTokensIntoLines(iterator.Tokens())
+	width := len(strconv.Itoa(len(lines)))
+	for idx, line := range lines {
+		fmt.Fprint(out, lineNumberText.Sprintf("%*d ", width, idx+1))
+		formatters.Get(formatter).Format(out, styles.Get(style), chroma.Literator(line...))
+	}
+}
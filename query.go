@@ -7,11 +7,11 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/quick"
 	"github.com/blevesearch/bleve"
 	"github.com/blevesearch/bleve/search"
 	"github.com/blevesearch/bleve/search/query"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 type libSummary struct {
@@ -32,7 +32,26 @@ type searchQuery struct {
 	starred  bool
 	status   string
 	limit    int
+	page     int
+	offset   int
+	fuzzy    string
+	facets   bool
 	debug    bool
+	// Date filters
+	since        string
+	until        string
+	createdSince string
+	createdUntil string
+	// Numeric filters
+	minLines    int
+	maxLines    int
+	files       string
+	minComments int
+	minSize     string
+	maxSize     string
+	// Local filters
+	pinned     bool
+	collection string
 }
 
 // Used to allow more flexibility when specifying sort.
@@ -55,6 +74,51 @@ var sortMap = map[string]string{
 	"createdat":   "CreatedAt",
 	"updated":     "UpdatedAt",
 	"n":           "NLines",
+	"lines":       "NLines",
+	"files":       "NFiles",
+	"comments":    "Comments",
+	"size":        "Size",
+	"last-opened": "LastOpened",
+	"frecency":    "-Frecency",
+	"pinned":      "-Pinned",
+}
+
+// sortKeys - parses a comma separated list of sort keys
+// (e.g. language,-updated,lines) into index fields.
+// A leading - reverses the order of a key.
+func sortKeys(s string) ([]string, error) {
+	var fields []string
+	for _, key := range strings.Split(strings.ToLower(s), ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		field, ok := sortMap[key]
+		if !ok {
+			field, ok = sortMap[strings.TrimPrefix(key, "-")]
+			if !ok || key[0] != '-' {
+				return nil, fmt.Errorf("Unknown sort key '%s'; Use one of: %s", key, strings.Join(sortKeyNames(), ", "))
+			}
+			if field[0] == '-' {
+				field = field[1:]
+			} else {
+				field = "-" + field
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func sortKeyNames() []string {
+	var names []string
+	for key := range sortMap {
+		if key != "" && key[0] != '-' {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	return names
 }
 
 func uniqueAttributes(gist *search.DocumentMatch, field string, counter map[string]bool) {
@@ -96,112 +160,264 @@ func librarySummary() libSummary {
 	return libSummary{gists: dc, files: nfiles, starred: nstarred, tags: len(ntags), languages: len(nlanguage), owners: len(nowners)}
 }
 
-// ls - the primary query interface
-func ls(search *searchQuery) {
+// filterQuery - builds a query string from the active filters
+func filterQuery(search *searchQuery) string {
 	var qstring string
-	var highlightTermSet []string
-	// Consider reworking filtering here to be done manually...
-	if search.term != "" {
-		qstring = fmt.Sprintf("%s", search.term)
-		// TODO [$5fdcfd44ecafc60007b09208]: Fix term splitting
-		// TODO [$5fdcfd44ecafc60007b09209]: Handle highlighting at field-level when filtering.
-		debugMsg(fmt.Sprint(strings.Split(search.term, " ")))
-		highlightTermSet = append(highlightTermSet, strings.Split(search.term, " ")...)
-		debugMsg(fmt.Sprintf("highlight- %+v", highlightTermSet))
-	}
-
-	if search.tag != "" {
-		qstring = fmt.Sprintf("+Tags:%v %s", search.tag, qstring)
-		highlightTermSet = append(highlightTermSet, "#"+search.tag)
-	}
-
 	if search.language != "" {
 		qstring = fmt.Sprintf("+Language:%v %s", search.language, qstring)
-		highlightTermSet = append(highlightTermSet, search.language)
 	}
 
 	if search.starred {
 		qstring = fmt.Sprintf("+Starred:T %s", qstring)
 	}
 
+	if search.pinned {
+		qstring = fmt.Sprintf("+Pinned:T %s", qstring)
+	}
+
 	if search.owner != "" {
 		qstring = fmt.Sprintf("+Owner:%v %s", search.owner, qstring)
-		highlightTermSet = append(highlightTermSet, search.owner)
 	}
 
 	if search.status == "public" {
 		qstring = fmt.Sprintf("+Public:T %s", qstring)
 	} else if search.status == "private" {
 		qstring = fmt.Sprintf("+Public:F %s", qstring)
-	} else if search.status != "all" {
+	} else if search.status != "all" && search.status != "" {
 		ThrowError("--public must be 'all', 'public', or 'private'", 1)
 	}
+	return strings.Trim(qstring, " ")
+}
 
-	debugMsg(fmt.Sprintf("Query: %s", qstring))
+// filterQueries - the active filters as a set of queries
+func filterQueries(search *searchQuery) []query.Query {
+	var filters []query.Query
+	if qstring := filterQuery(search); qstring != "" {
+		filters = append(filters, query.NewQueryStringQuery(qstring))
+	}
+	if search.tag != "" {
+		// Matches the tag and its children
+		q := query.NewTermQuery(strings.ToLower(strings.TrimPrefix(search.tag, "#")))
+		q.SetField("TagPaths")
+		filters = append(filters, q)
+	}
+	if search.collection != "" {
+		q := query.NewTermQuery(strings.ToLower(search.collection))
+		q.SetField("Collections")
+		filters = append(filters, q)
+	}
+	if q := dateRangeQuery("UpdatedAt", search.since, search.until); q != nil {
+		filters = append(filters, q)
+	}
+	if q := dateRangeQuery("CreatedAt", search.createdSince, search.createdUntil); q != nil {
+		filters = append(filters, q)
+	}
+	filters = append(filters, numericFilters(search)...)
+	return filters
+}
+
+// andQuery - requires q (which may be nil) and all filters to match
+func andQuery(q query.Query, filters []query.Query) query.Query {
+	var conjuncts []query.Query
+	if q != nil {
+		conjuncts = append(conjuncts, q)
+	}
+	conjuncts = append(conjuncts, filters...)
+	if len(conjuncts) == 0 {
+		return query.NewMatchAllQuery()
+	} else if len(conjuncts) == 1 {
+		return conjuncts[0]
+	}
+	return query.NewConjunctionQuery(conjuncts)
+}
+
+// sortResults - applies --sort or the default ordering
+func sortResults(sr *bleve.SearchRequest, search *searchQuery, isQuery bool) {
+	if search.sort != "" {
+		sortBy, err := sortKeys(search.sort)
+		if err != nil {
+			ThrowError(err.Error(), 1)
+		}
+		sr.SortBy(append(sortBy, "-_score"))
+	} else if isQuery == true {
+		sr.SortBy([]string{"-_score"})
+	} else if isQuery == false {
+		// Pinned gists are listed first
+		sr.SortBy([]string{"-Pinned", "-UpdatedAt"})
+	}
+}
+
+// pageFrom - index of the first result for --page and --offset
+func pageFrom(search *searchQuery) int {
+	page := search.page
+	if page < 1 {
+		page = 1
+	}
+	from := search.offset + (page-1)*search.limit
+	if from < 0 {
+		return 0
+	}
+	return from
+}
+
+// runQuery - runs a query and returns the results. The second return
+// value is true for queries with search terms or filters, and the
+// third is true when the results come from fuzzy matching.
+func runQuery(search *searchQuery) (*bleve.SearchResult, bool, bool, error) {
+	// Consider reworking filtering here to be done manually...
+	if search.term != "" {
+		// TODO [$5fdcfd44ecafc60007b09208]: Fix term splitting
+		debugMsg(fmt.Sprint(strings.Split(search.term, " ")))
+	}
+
+	if contains([]string{"", "auto", "on", "off"}, search.fuzzy) == false {
+		ThrowError("--fuzzy must be 'off', 'auto', or 'on'", 1)
+	}
+
+	filters := filterQueries(search)
+
+	debugMsg(fmt.Sprintf("Query: %s %s", filterQuery(search), search.term))
 	debugMsg(fmt.Sprintf("%+v", search))
 
-	qstring = strings.Trim(qstring, " ")
+	// Fuzzy matching replaces the exact query
+	if search.fuzzy == "on" && search.term != "" {
+		results, err := fuzzySearch(search, filters)
+		return results, true, true, err
+	}
+
 	var isQuery bool
 	var sr *bleve.SearchRequest
 
 	// dump when no query params present
-	if search.term == "" && qstring == "" && search.status == "all" {
+	if search.term == "" && len(filters) == 0 {
 		q := query.NewMatchAllQuery()
 		sr = bleve.NewSearchRequest(q)
 		sr.Size = search.limit
+		sr.From = pageFrom(search)
 		isQuery = false
 	} else {
-		q := query.NewQueryStringQuery(qstring)
-		sr = bleve.NewSearchRequest(q)
+		var q query.Query
+		if search.term != "" {
+			q = query.NewQueryStringQuery(expandFieldAliases(search.term))
+			if symbols := symbolQuery(search.term); symbols != nil {
+				// Rank gists declaring a term first
+				q = query.NewBooleanQuery([]query.Query{q}, []query.Query{symbols}, nil)
+			}
+		}
+		sr = bleve.NewSearchRequest(andQuery(q, filters))
 		sr.Size = search.limit
+		sr.From = pageFrom(search)
+		sr.Highlight = bleve.NewHighlightWithStyle(fragmentHighlighter)
 		isQuery = true
 	}
 
-	// Handle sorting
-	if search.sort != "" {
-		sortBy := sortMap[search.sort]
-		if sortBy == "" && search.sort[0] == '-' {
-			sortBy = fmt.Sprintf("-%s", sortMap[strings.Trim(search.sort, "-")])
-		}
-		sr.SortBy([]string{sortBy, "-_score"})
-	} else if isQuery == true {
-		sr.SortBy([]string{"-_score"})
-	} else if isQuery == false {
-		sr.SortBy([]string{"-UpdatedAt"})
-	}
+	sortResults(sr, search, isQuery)
+	addFacets(sr, search)
 
 	sr.Fields = []string{"*"}
 	results, err := dbIdx.Search(sr)
-	if err != nil || len(results.Hits) == 0 {
+	if (err != nil || len(results.Hits) == 0) && search.fuzzy != "off" && search.term != "" {
 		// If no results, try fuzzy search
-		fuzzySearch(search.term)
+		results, err = fuzzySearch(search, filters)
+		return results, isQuery, true, err
+	}
+	return results, isQuery, false, err
+}
+
+// fuzzyNotice - shown when results come from fuzzy matching
+func fuzzyNotice(search *searchQuery) string {
+	if search.fuzzy == "on" {
+		return "Showing fuzzy results"
+	}
+	return "No exact matches, showing fuzzy results"
+}
+
+// ls - the primary query interface
+func ls(search *searchQuery) {
+	results, isQuery, isFuzzy, err := runQuery(search)
+	if err == nil && isDataFormat() {
+		writeResults(results, search)
+		return
+	}
+	if err != nil || len(results.Hits) == 0 {
+		errorMsg("No Results\n")
 		os.Exit(0)
 	}
 
+	if isFuzzy && outputFormat == "console" {
+		boldMsg(fuzzyNotice(search) + "\n")
+	}
+
 	if outputFormat == "console" {
-		resultTable(results, isQuery, highlightTermSet)
+		resultTable(results, isQuery, search.offset)
+		saveLastResults(results, isQuery, search.offset)
+		if search.facets {
+			facetSummary(results)
+		}
+		// Prompt for the next page when run interactively
+		for hasNextPage(results) && outputPipe() == false && terminal.IsTerminal(int(os.Stdin.Fd())) {
+			if morePrompt() == false {
+				break
+			}
+			if search.page < 1 {
+				search.page = 1
+			}
+			search.page++
+			if results, isQuery, _, err = runQuery(search); err != nil || len(results.Hits) == 0 {
+				break
+			}
+			resultTable(results, isQuery, search.offset)
+			saveLastResults(results, isQuery, search.offset)
+		}
 	} else if outputFormat == "alfred" {
 		resultListAlfred(results)
 	}
 }
 
+// Fuzziness allowed for a term; short terms are too
+// ambiguous to match with edits.
+func termFuzziness(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 1
+	}
+	return 2
+}
+
+// fuzzyQuery - each term matches exactly, as a prefix, or within
+// an edit distance; exact and prefix matches are ranked higher.
+// Gists matching more terms are ranked higher.
+func fuzzyQuery(terms string) *query.DisjunctionQuery {
+	var termQueries []query.Query
+	for _, term := range strings.Fields(strings.ToLower(terms)) {
+		match := query.NewMatchQuery(term)
+		match.SetBoost(3)
+		prefix := query.NewPrefixQuery(term)
+		prefix.SetBoost(2)
+		termQuery := query.NewDisjunctionQuery([]query.Query{match, prefix})
+		if fuzziness := termFuzziness(term); fuzziness > 0 {
+			fuzzy := query.NewFuzzyQuery(term)
+			fuzzy.SetFuzziness(fuzziness)
+			termQuery.AddQuery(fuzzy)
+		}
+		termQueries = append(termQueries, termQuery)
+	}
+	return query.NewDisjunctionQuery(termQueries)
+}
+
 // Perform fuzzy search
-func fuzzySearch(searchTerm string) {
-	var isQuery bool
-	var sr *bleve.SearchRequest
-	q := query.NewFuzzyQuery(searchTerm)
-	q.SetFuzziness(2)
-	sr = bleve.NewSearchRequest(q)
-	sr.Size = 10
-	isQuery = true
+func fuzzySearch(search *searchQuery, filters []query.Query) (*bleve.SearchResult, error) {
+	sr := bleve.NewSearchRequest(andQuery(fuzzyQuery(search.term), filters))
+	sr.Size = search.limit
+	sr.From = pageFrom(search)
+	sr.Highlight = bleve.NewHighlightWithStyle(fragmentHighlighter)
+	sortResults(sr, search, true)
+	addFacets(sr, search)
 
 	sr.Fields = []string{"*"}
-	results, err := dbIdx.Search(sr)
-	if err != nil {
-		errorMsg("No Results\n")
-		os.Exit(0)
-	}
-	resultTable(results, isQuery, []string{})
+	return dbIdx.Search(sr)
 }
 
 func highlight(out io.Writer, filename string, content string, formatter string, style string) {
@@ -217,13 +433,7 @@ func highlight(out io.Writer, filename string, content string, formatter string,
 			terminal256
 			tokens
 	*/
-	lexer := lexers.Match(filename)
-	if lexer == nil || lexer.Config().Name == "plaintext" {
-		lexer = lexers.Analyse(content)
-		if lexer == nil {
-			lexer = lexers.Fallback
-		}
-	}
+	lexer := fileLexer(filename, content)
 	quick.Highlight(out, content, lexer.Config().Name, formatter, style)
 }
 
@@ -239,6 +449,22 @@ func lookupGist(gistIdx int) *search.DocumentMatch {
 	return searchResults.Hits[0]
 }
 
+// termGists - returns all gists with term in field
+func termGists(field string, term string) []*search.DocumentMatch {
+	q := query.NewTermQuery(term)
+	q.SetField(field)
+	dc, _ := dbIdx.DocCount()
+	sr := bleve.NewSearchRequest(q)
+	sr.Size = int(dc)
+	sr.Fields = []string{"*"}
+	sr.SortBy([]string{"IDX"})
+	results, err := dbIdx.Search(sr)
+	if err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+	return results.Hits
+}
+
 // Returns the next IDX to use
 func nextIdx() int {
 	dc, _ := dbIdx.DocCount()
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search/query"
+)
+
+func TestSortKeys(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"updated", []string{"UpdatedAt"}},
+		{"-updated", []string{"-UpdatedAt"}},
+		{"language,-updated, lines", []string{"Language", "-UpdatedAt", "NLines"}},
+		{"Starred", []string{"-Starred"}},
+		{"-starred", []string{"Starred"}},
+		{"-frecency", []string{"Frecency"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := sortKeys(tt.s)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sortKeys(%q) = %q, %v; want %q", tt.s, got, err, tt.want)
+		}
+	}
+}
+
+func TestSortKeysUnknown(t *testing.T) {
+	for _, s := range []string{"stars", "-stars", "updated,stars", "--updated"} {
+		got, err := sortKeys(s)
+		if err == nil {
+			t.Errorf("sortKeys(%q) = %q; want an error", s, got)
+			continue
+		}
+		if !strings.Contains(err.Error(), "Unknown sort key") || !strings.Contains(err.Error(), "frecency") {
+			t.Errorf("sortKeys(%q) error = %q; want the unknown key and valid keys", s, err)
+		}
+	}
+}
+
+func TestFuzzyQuery(t *testing.T) {
+	tests := []struct {
+		terms     string
+		fuzziness []int
+	}{
+		{"", []int{}},
+		// Short terms are only matched exactly or as a prefix
+		{"s3 aws", []int{0, 1}},
+		{"Upload BOTO3client", []int{2, 2}},
+	}
+	for _, tt := range tests {
+		terms := strings.Fields(strings.ToLower(tt.terms))
+		disjuncts := fuzzyQuery(tt.terms).Disjuncts
+		if len(disjuncts) != len(terms) {
+			t.Errorf("fuzzyQuery(%q) has %v terms; want %v", tt.terms, len(disjuncts), len(terms))
+			continue
+		}
+		for i, term := range terms {
+			termQuery := disjuncts[i].(*query.DisjunctionQuery).Disjuncts
+			match := termQuery[0].(*query.MatchQuery)
+			prefix := termQuery[1].(*query.PrefixQuery)
+			if match.Match != term || match.Boost() != 3 || prefix.Prefix != term || prefix.Boost() != 2 {
+				t.Errorf("fuzzyQuery(%q) %q matches %q (boost %v) and prefix %q (boost %v)", tt.terms, term, match.Match, match.Boost(), prefix.Prefix, prefix.Boost())
+			}
+			if tt.fuzziness[i] == 0 {
+				if len(termQuery) != 2 {
+					t.Errorf("fuzzyQuery(%q) %q has a fuzzy query", tt.terms, term)
+				}
+				continue
+			}
+			if len(termQuery) != 3 {
+				t.Errorf("fuzzyQuery(%q) %q has no fuzzy query", tt.terms, term)
+				continue
+			}
+			if fuzzy := termQuery[2].(*query.FuzzyQuery); fuzzy.Term != term || fuzzy.Fuzziness != tt.fuzziness[i] {
+				t.Errorf("fuzzyQuery(%q) %q is fuzzy %q within %v; want within %v", tt.terms, term, fuzzy.Term, fuzzy.Fuzziness, tt.fuzziness[i])
+			}
+		}
+	}
+}
+
+func TestFuzzyQueryMatches(t *testing.T) {
+	index, err := bleve.NewMemOnly(indexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer index.Close()
+	for id, description := range map[string]string{
+		"upload": "Upload files to s3",
+		"bam":    "Read a bam file",
+	} {
+		if err := index.Index(id, Snippet{ID: id, Description: description}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		terms string
+		want  []string
+	}{
+		{"upload", []string{"upload"}},
+		{"uplaod", []string{"upload"}},
+		{"uplo", []string{"upload"}},
+		{"bam", []string{"bam"}},
+		// Gists matching more terms are ranked higher
+		{"uplod bam file", []string{"bam", "upload"}},
+		{"filez uplod", []string{"upload", "bam"}},
+		{"zzz", []string{}},
+	}
+	for _, tt := range tests {
+		results, err := index.Search(bleve.NewSearchRequest(fuzzyQuery(tt.terms)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, hit := range results.Hits {
+			got = append(got, hit.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fuzzyQuery(%q) matches %q; want %q", tt.terms, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/query"
+)
+
+// Number of content terms used to find related gists
+const relatedTerms = 10
+
+var contentWord = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_]{2,}`)
+
+// Words too common in code to indicate a subject
+var commonWords = map[string]bool{
+	"and": true, "the": true, "for": true, "with": true, "from": true,
+	"this": true, "that": true, "not": true, "are": true, "you": true,
+	"def": true, "return": true, "import": true, "function": true, "var": true,
+	"let": true, "const": true, "true": true, "false": true, "null": true,
+	"none": true, "nil": true, "else": true, "elif": true, "then": true,
+	"echo": true, "print": true, "self": true, "new": true, "class": true,
+	"int": true, "string": true, "func": true, "end": true, "done": true,
+}
+
+// salientTerms - returns the content terms that are frequent in the
+// gist and rare across the library.
+func salientTerms(gist *search.DocumentMatch, n int) []string {
+	counts := map[string]int{}
+	for _, file := range parseGistFiles(gist) {
+		for _, word := range contentWord.FindAllString(file["content"], -1) {
+			word = strings.ToLower(word)
+			if commonWords[word] == false {
+				counts[word]++
+			}
+		}
+	}
+
+	dc, _ := dbIdx.DocCount()
+	weights := map[string]float64{}
+	terms := make([]string, 0, len(counts))
+	for term, count := range counts {
+		docFreq := termDocFreq(term)
+		if docFreq == 0 {
+			continue
+		}
+		weights[term] = float64(count) * float64(dc) / float64(docFreq)
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if weights[terms[i]] == weights[terms[j]] {
+			return terms[i] < terms[j]
+		}
+		return weights[terms[i]] > weights[terms[j]]
+	})
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
+
+// termDocFreq - returns the number of gists containing term
+func termDocFreq(term string) uint64 {
+	dict, err := dbIdx.FieldDictRange("_all", []byte(term), []byte(term))
+	if err != nil {
+		return 0
+	}
+	defer dict.Close()
+	entry, err := dict.Next()
+	if err != nil || entry == nil {
+		return 0
+	}
+	return entry.Count
+}
+
+// relatedQuery - matches gists sharing a description, tags,
+// filenames, languages, or salient terms with gist;
+// the gist itself is excluded.
+func relatedQuery(gist *search.DocumentMatch) query.Query {
+	var queries []query.Query
+	addMatch := func(field string, text string, boost float64) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		q := query.NewMatchQuery(text)
+		if field != "" {
+			q.SetField(field)
+		}
+		q.SetBoost(boost)
+		queries = append(queries, q)
+	}
+
+	addMatch("Description", gist.Fields["Description"].(string), 2)
+	for _, tag := range fieldStrings(gist, "Tags") {
+		addMatch("Tags", tag, 3)
+	}
+	for _, filename := range fieldStrings(gist, "Filename") {
+		addMatch("Filename", filename, 1)
+	}
+	for _, language := range fieldStrings(gist, "Language") {
+		addMatch("Language", language, 0.5)
+	}
+	for _, term := range salientTerms(gist, relatedTerms) {
+		addMatch("", term, 1)
+	}
+
+	self := query.NewDocIDQuery([]string{gist.ID})
+	return query.NewBooleanQuery(
+		[]query.Query{query.NewDisjunctionQuery(queries)},
+		nil,
+		[]query.Query{self},
+	)
+}
+
+// relatedGists - finds gists on the same subject as gistIdx
+func relatedGists(gistIdx int, search *searchQuery) *bleve.SearchResult {
+	gist := lookupGist(gistIdx)
+	sr := bleve.NewSearchRequest(andQuery(relatedQuery(gist), filterQueries(search)))
+	sr.Size = search.limit
+	sr.From = pageFrom(search)
+	sr.Fields = []string{"*"}
+	sr.SortBy([]string{"-_score"})
+	results, err := dbIdx.Search(sr)
+	if err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+	return results
+}
+
+func related(gistIdx int, search *searchQuery) {
+	results := relatedGists(gistIdx, search)
+	if len(results.Hits) == 0 {
+		errorMsg("No Results\n")
+		return
+	}
+	if outputFormat == "console" {
+		resultTable(results, true, search.offset)
+		saveLastResults(results, true, search.offset)
+	} else if outputFormat == "alfred" {
+		resultListAlfred(results)
+	}
+}
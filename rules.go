@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/query"
+)
+
+// tagRule - derives a tag for gists matching every condition given.
+// Filename is a glob, language is matched regardless of case, and
+// content is a regular expression. Filename, language, and content
+// must match the same file.
+type tagRule struct {
+	Tag      string `json:"tag"`
+	Filename string `json:"filename,omitempty"`
+	Language string `json:"language,omitempty"`
+	Content  string `json:"content,omitempty"`
+	Owner    string `json:"owner,omitempty"`
+	content  *regexp.Regexp
+}
+
+// Rules are read from the config once
+var loadedTagRules []tagRule
+
+func loadTagRules() []tagRule {
+	if loadedTagRules != nil {
+		return loadedTagRules
+	}
+	config, _ := getConfig()
+	loadedTagRules = []tagRule{}
+	for _, rule := range config.TagRules {
+		rule.Tag = validTag(rule.Tag)
+		if _, err := filepath.Match(rule.Filename, ""); err != nil {
+			ThrowError(fmt.Sprintf("Invalid filename in tag rule #%s: %s", rule.Tag, err), 1)
+		}
+		if rule.Content != "" {
+			content, err := regexp.Compile(rule.Content)
+			if err != nil {
+				ThrowError(fmt.Sprintf("Invalid content in tag rule #%s: %s", rule.Tag, err), 1)
+			}
+			rule.content = content
+		}
+		loadedTagRules = append(loadedTagRules, rule)
+	}
+	return loadedTagRules
+}
+
+func (rule tagRule) hasFileConditions() bool {
+	return rule.Filename != "" || rule.Language != "" || rule.Content != ""
+}
+
+func (rule tagRule) matchFile(filename string, language string, content string) bool {
+	if rule.Filename != "" {
+		if matched, _ := filepath.Match(rule.Filename, filename); matched == false {
+			return false
+		}
+	}
+	if rule.Language != "" && strings.EqualFold(rule.Language, language) == false {
+		return false
+	}
+	if rule.content != nil && rule.content.MatchString(content) == false {
+		return false
+	}
+	return true
+}
+
+func (rule tagRule) match(sn Snippet) bool {
+	if rule.Owner != "" && strings.EqualFold(rule.Owner, sn.Owner) == false {
+		return false
+	}
+	if rule.hasFileConditions() == false {
+		return true
+	}
+	for filename, file := range sn.Files {
+		var language, content string
+		if file.Language != nil {
+			language = *file.Language
+		}
+		if file.Content != nil {
+			content = *file.Content
+		}
+		if rule.matchFile(string(filename), language, content) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleTags - returns the tags derived by rules that are
+// not already in the description
+func ruleTags(sn Snippet) []string {
+	tags := []string{}
+	for _, rule := range loadTagRules() {
+		if rule.match(sn) == false || hasTag(sn.Description, rule.Tag) {
+			continue
+		}
+		seen := false
+		for _, tag := range tags {
+			seen = seen || strings.EqualFold(tag, rule.Tag)
+		}
+		if seen == false {
+			tags = append(tags, rule.Tag)
+		}
+	}
+	return tags
+}
+
+// applyTagRules - sets the tags derived by rules; filtering
+// by tag matches description and derived tags alike
+func applyTagRules(sn Snippet) Snippet {
+	sn.RuleTags = ruleTags(sn)
+	sn.TagPaths = tagPaths(append(append([]string{}, sn.Tags...), sn.RuleTags...))
+	return sn
+}
+
+// ruleTagString - derived tags as they are shown after descriptions
+func ruleTagString(gist *search.DocumentMatch) string {
+	var tags []string
+	for _, tag := range fieldStrings(gist, "RuleTags") {
+		tags = append(tags, "#"+tag)
+	}
+	return strings.Join(tags, " ")
+}
+
+func ruleTagTable(gists []*search.DocumentMatch) {
+	data := make([][]string, len(gists))
+	for i, gist := range gists {
+		data[i] = []string{
+			fmt.Sprintf("%v", int(gist.Fields["IDX"].(float64))),
+			gist.Fields["Description"].(string),
+			ruleTagText.Sprint(ruleTagString(gist)),
+		}
+	}
+	renderTable([]string{"ID", "Description", "Rule Tags"}, data)
+}
+
+// reindexTagRules - applies the current rules to every gist in the
+// index and returns the gists with derived tags. With dryRun the
+// index is left unchanged.
+func reindexTagRules(dryRun bool) []*search.DocumentMatch {
+	dc, _ := dbIdx.DocCount()
+	sr := bleve.NewSearchRequest(query.NewMatchAllQuery())
+	sr.Size = int(dc)
+	sr.Fields = []string{"*"}
+	sr.SortBy([]string{"IDX"})
+	results, err := dbIdx.Search(sr)
+	if err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+
+	batch := dbIdx.NewBatch()
+	var tagged []*search.DocumentMatch
+	for _, gist := range results.Hits {
+		sn := applyTagRules(snippetFromDoc(gist))
+		if strings.Join(sn.RuleTags, " ") != strings.Join(fieldStrings(gist, "RuleTags"), " ") {
+			batch.Index(sn.ID, sn)
+		}
+		if len(sn.RuleTags) > 0 {
+			if dryRun {
+				// Show the derived tags without indexing them
+				ruleTags := make([]interface{}, len(sn.RuleTags))
+				for i, tag := range sn.RuleTags {
+					ruleTags[i] = tag
+				}
+				gist.Fields["RuleTags"] = ruleTags
+			}
+			tagged = append(tagged, gist)
+		}
+	}
+	if dryRun {
+		return tagged
+	}
+	if batch.Size() > 0 {
+		if err := dbIdx.Batch(batch); err != nil {
+			ThrowError(fmt.Sprintf("Error: %s", err), 1)
+		}
+	}
+	// Return the updated records
+	for i, gist := range tagged {
+		tagged[i] = lookupGist(int(gist.Fields["IDX"].(float64)))
+	}
+	return tagged
+}
+
+// applyRules - updates derived tags after the rules change and
+// optionally writes them into the descriptions of your gists
+func applyRules(push bool, dryRun bool, yes bool) {
+	tagged := reindexTagRules(dryRun)
+	if len(tagged) == 0 {
+		successMsg("No gists match tag rules\n")
+		return
+	}
+	if push == false {
+		ruleTagTable(tagged)
+		return
+	}
+	var changes []descriptionChange
+	for _, gist := range tagged {
+		tags := fieldStrings(gist, "RuleTags")
+		changes = append(changes, tagChanges([]*search.DocumentMatch{gist}, func(description string) string {
+			for _, tag := range tags {
+				description = strings.TrimSpace(description + " #" + tag)
+			}
+			return description
+		})...)
+	}
+	applyDescriptionChanges(changes, dryRun, yes)
+}
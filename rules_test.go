@@ -0,0 +1,89 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+// ruleSnippet - a gist with files given as name, language, content
+func ruleSnippet(owner string, files ...string) Snippet {
+	sn := Snippet{Owner: owner, Files: map[github.GistFilename]github.GistFile{}}
+	for i := 0; i+2 < len(files); i += 3 {
+		sn.Files[github.GistFilename(files[i])] = github.GistFile{
+			Language: github.String(files[i+1]),
+			Content:  github.String(files[i+2]),
+		}
+	}
+	return sn
+}
+
+func TestTagRuleMatch(t *testing.T) {
+	upload := ruleSnippet("octocat",
+		"upload.py", "Python", "import boto3\ns3 = boto3.client('s3')",
+		"README.md", "Markdown", "Uploads files to s3")
+	tests := []struct {
+		rule tagRule
+		want bool
+	}{
+		// Glob
+		{tagRule{Filename: "*.py"}, true},
+		{tagRule{Filename: "upload.*"}, true},
+		{tagRule{Filename: "*.sh"}, false},
+		{tagRule{Filename: "*.PY"}, false},
+		// Language, regardless of case
+		{tagRule{Language: "python"}, true},
+		{tagRule{Language: "Go"}, false},
+		// Regular expression
+		{tagRule{Content: `boto3\.client`}, true},
+		{tagRule{Content: `^import`}, true},
+		{tagRule{Content: `^s3`}, false},
+		// Conditions must match the same file
+		{tagRule{Filename: "*.py", Content: "boto3"}, true},
+		{tagRule{Filename: "*.md", Content: "boto3"}, false},
+		{tagRule{Language: "markdown", Content: "Uploads"}, true},
+		{tagRule{Language: "python", Content: "Uploads"}, false},
+		// Owner
+		{tagRule{Owner: "OctoCat"}, true},
+		{tagRule{Owner: "someone", Filename: "*.py"}, false},
+		{tagRule{Owner: "octocat", Filename: "*.py"}, true},
+	}
+	for _, tt := range tests {
+		if tt.rule.Content != "" {
+			tt.rule.content = regexp.MustCompile(tt.rule.Content)
+		}
+		if got := tt.rule.match(upload); got != tt.want {
+			t.Errorf("%+v match = %v; want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestApplyTagRules(t *testing.T) {
+	defer func(rules []tagRule) { loadedTagRules = rules }(loadedTagRules)
+	loadedTagRules = []tagRule{
+		{Tag: "python", Language: "python"},
+		{Tag: "aws/s3", Content: "boto3", content: regexp.MustCompile("boto3")},
+		{Tag: "Python", Filename: "*.py"},
+		{Tag: "shell", Filename: "*.sh"},
+	}
+	tests := []struct {
+		description string
+		wantRule    []string
+		wantPaths   []string
+	}{
+		{"Upload to s3", []string{"python", "aws/s3"}, []string{"python", "aws", "aws/s3"}},
+		// Tags already in the description are not derived again
+		{"Upload #aws/s3", []string{"python"}, []string{"aws", "aws/s3", "python"}},
+	}
+	for _, tt := range tests {
+		sn := ruleSnippet("octocat", "upload.py", "Python", "import boto3")
+		sn.Description = tt.description
+		sn.Tags = parseTags(tt.description)
+		sn = applyTagRules(sn)
+		if !reflect.DeepEqual(sn.RuleTags, tt.wantRule) || !reflect.DeepEqual(sn.TagPaths, tt.wantPaths) {
+			t.Errorf("applyTagRules(%q) = %q, %q; want %q, %q", tt.description, sn.RuleTags, sn.TagPaths, tt.wantRule, tt.wantPaths)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Saved searches are stored as the arguments passed to ls
+var libSavedPath = fmt.Sprintf("%s/saved.json", getLibraryDirectory())
+
+// Names must start with a letter to distinguish them from @1 style references
+var savedName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+func loadSavedSearches() map[string][]string {
+	saved := map[string][]string{}
+	data, err := ioutil.ReadFile(libSavedPath)
+	if err != nil {
+		return saved
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		ThrowError(fmt.Sprintf("Error reading %s", libSavedPath), 1)
+	}
+	return saved
+}
+
+func writeSavedSearches(saved map[string][]string) {
+	_ = os.Mkdir(getLibraryDirectory(), 0755)
+	out, err := json.Marshal(saved)
+	check(err)
+	err = ioutil.WriteFile(libSavedPath, out, 0644)
+	check(err)
+}
+
+func savedSearchNames(saved map[string][]string) []string {
+	names := make([]string, 0, len(saved))
+	for name := range saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitArgs - separates ls flags (and their values) from search terms
+// so that flags can be placed before terms when arguments are combined.
+func splitArgs(args []string) ([]string, []string) {
+	var flags []string
+	var terms []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) < 2 || arg[0] != '-' {
+			terms = append(terms, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		for _, flag := range lsFlags {
+			if _, isBool := flag.(*cli.BoolFlag); contains(flag.Names(), name) && !isBool {
+				// Consume the flag value
+				if i+1 < len(args) {
+					flags = append(flags, args[i+1])
+					i++
+				}
+				break
+			}
+		}
+	}
+	return flags, terms
+}
+
+// setsFlag - whether flag is among the flags of args
+func setsFlag(args []string, flag cli.Flag) bool {
+	flags, _ := splitArgs(args)
+	for _, arg := range flags {
+		if len(arg) < 2 || arg[0] != '-' {
+			// A flag value
+			continue
+		}
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if contains(flag.Names(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQueryArgs - parses ls arguments into a searchQuery
+func parseQueryArgs(args []string) (searchQuery, error) {
+	var result searchQuery
+	app := &cli.App{
+		Name:      "gg",
+		Flags:     lsFlags,
+		Writer:    ioutil.Discard,
+		ErrWriter: ioutil.Discard,
+		Action: func(c *cli.Context) error {
+			fillQuery(&result, c)
+			result.term = strings.Join(c.Args().Slice(), " ")
+			return nil
+		},
+	}
+	err := app.Run(append([]string{"gg"}, args...))
+	return result, err
+}
+
+// savedSearchArgs - returns the ls arguments for a saved search
+// combined with any additional arguments.
+func savedSearchArgs(name string, extra []string) []string {
+	savedArgs, ok := loadSavedSearches()[name]
+	if !ok {
+		ThrowError(fmt.Sprintf("No saved search named '%s'. Run 'gg saved list'", name), 1)
+	}
+	return combineArgs(savedArgs, extra)
+}
+
+// combineArgs - places the flags of both sets of arguments
+// before their terms
+func combineArgs(savedArgs []string, extra []string) []string {
+	savedFlags, savedTerms := splitArgs(savedArgs)
+	extraFlags, extraTerms := splitArgs(extra)
+	var args []string
+	args = append(args, savedFlags...)
+	args = append(args, extraFlags...)
+	args = append(args, savedTerms...)
+	return append(args, extraTerms...)
+}
+
+func addSavedSearch(name string, args []string) {
+	if savedName.MatchString(name) == false {
+		ThrowError("Saved search names must start with a letter and contain only letters, numbers, - or _", 1)
+	}
+	if _, err := parseQueryArgs(args); err != nil {
+		ThrowError(fmt.Sprintf("Invalid query: %s", err), 1)
+	}
+	saved := loadSavedSearches()
+	saved[name] = args
+	writeSavedSearches(saved)
+	successMsg(fmt.Sprintf("Saved @%s\n", name))
+}
+
+func rmSavedSearch(name string) {
+	saved := loadSavedSearches()
+	if _, ok := saved[name]; !ok {
+		ThrowError(fmt.Sprintf("No saved search named '%s'", name), 1)
+	}
+	delete(saved, name)
+	writeSavedSearches(saved)
+	successMsg(fmt.Sprintf("Removed @%s\n", name))
+}
+
+func savedSearchTable() {
+	saved := loadSavedSearches()
+	data := make([][]string, 0, len(saved))
+	for _, name := range savedSearchNames(saved) {
+		data = append(data, []string{"@" + name, strings.Join(saved[name], " ")})
+	}
+
+	renderTable([]string{"Name", "Query"}, data)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantFlags []string
+		wantTerms []string
+	}{
+		{[]string{"bam"}, nil, []string{"bam"}},
+		{[]string{"--tag", "aws", "s3"}, []string{"--tag", "aws"}, []string{"s3"}},
+		{[]string{"-t", "aws", "s3"}, []string{"-t", "aws"}, []string{"s3"}},
+		{[]string{"--tag=aws", "s3"}, []string{"--tag=aws"}, []string{"s3"}},
+		// Boolean flags do not take a value
+		{[]string{"--starred", "s3"}, []string{"--starred"}, []string{"s3"}},
+		{[]string{"s3", "--limit", "5", "upload"}, []string{"--limit", "5"}, []string{"s3", "upload"}},
+		{[]string{"-"}, nil, []string{"-"}},
+	}
+	for _, tt := range tests {
+		flags, terms := splitArgs(tt.args)
+		if !reflect.DeepEqual(flags, tt.wantFlags) || !reflect.DeepEqual(terms, tt.wantTerms) {
+			t.Errorf("splitArgs(%q) = %q, %q; want %q, %q", tt.args, flags, terms, tt.wantFlags, tt.wantTerms)
+		}
+	}
+}
+
+func TestCombineArgs(t *testing.T) {
+	tests := []struct {
+		saved []string
+		extra []string
+		want  []string
+	}{
+		{[]string{"--tag", "aws", "s3"}, nil, []string{"--tag", "aws", "s3"}},
+		{[]string{"--tag", "aws", "s3"}, []string{"upload"}, []string{"--tag", "aws", "s3", "upload"}},
+		{[]string{"s3"}, []string{"upload", "--limit", "5"}, []string{"--limit", "5", "s3", "upload"}},
+		{[]string{"--language", "python"}, []string{"--starred"}, []string{"--language", "python", "--starred"}},
+	}
+	for _, tt := range tests {
+		if got := combineArgs(tt.saved, tt.extra); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("combineArgs(%q, %q) = %q; want %q", tt.saved, tt.extra, got, tt.want)
+		}
+	}
+}
+
+func TestSetsFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"--limit", "5", "s3"}, true},
+		{[]string{"-l", "5"}, true},
+		{[]string{"--limit=5"}, true},
+		{[]string{"--tag", "aws", "s3"}, false},
+		// A value that looks like the flag is not the flag
+		{[]string{"--tag", "limit"}, false},
+		{[]string{"limit"}, false},
+	}
+	for _, tt := range tests {
+		if got := setsFlag(tt.args, &limitFlag); got != tt.want {
+			t.Errorf("setsFlag(%q, limit) = %v; want %v", tt.args, got, tt.want)
+		}
+	}
+}
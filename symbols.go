@@ -0,0 +1,116 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/blevesearch/bleve/search/query"
+)
+
+// Boost applied to search terms matching a symbol
+const symbolBoost = 3.0
+
+var symbolName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]+$`)
+
+// Keywords that are followed by the name being declared
+var declarationKeywords = map[string]bool{
+	"def": true, "class": true, "function": true, "func": true, "fn": true,
+	"let": true, "const": true, "var": true, "type": true, "struct": true,
+	"interface": true, "sub": true, "local": true, "declare": true, "alias": true,
+	"module": true, "enum": true, "trait": true, "impl": true,
+}
+
+// Shortcut for fields in search queries
+var symbolField = regexp.MustCompile(`(^|\s)([+-]?)sym:`)
+
+// fileLexer - returns the lexer for a file based on its
+// name, falling back to its content
+func fileLexer(filename string, content string) chroma.Lexer {
+	lexer := lexers.Match(filename)
+	if lexer == nil || lexer.Config().Name == "plaintext" {
+		lexer = lexers.Analyse(content)
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+	}
+	return lexer
+}
+
+// extractSymbols - returns the function, class, and variable
+// names declared in a file
+func extractSymbols(filename string, content string) []string {
+	iterator, err := fileLexer(filename, content).Tokenise(nil, content)
+	if err != nil {
+		return []string{}
+	}
+	var tokens []chroma.Token
+	for _, token := range iterator.Tokens() {
+		// Whitespace is only kept to find the start of lines
+		if strings.TrimSpace(token.Value) == "" && strings.Contains(token.Value, "\n") == false {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+
+	found := map[string]bool{}
+	for i, token := range tokens {
+		name := strings.TrimPrefix(strings.TrimSpace(token.Value), "$")
+		if symbolName.MatchString(name) == false || declarationKeywords[name] {
+			continue
+		}
+		switch {
+		case token.Type == chroma.NameFunction,
+			token.Type == chroma.NameClass,
+			token.Type >= chroma.NameVariable && token.Type <= chroma.NameVariableMagic:
+			found[name] = true
+		case token.Type.InCategory(chroma.Name) || token.Type.InCategory(chroma.Text):
+			var prev, next string
+			if i > 0 {
+				prev = tokens[i-1].Value
+			}
+			if i+1 < len(tokens) {
+				next = strings.TrimSpace(tokens[i+1].Value)
+			}
+			lineStart := i == 0 || (strings.Contains(prev, "\n") && strings.TrimSpace(prev) == "")
+			if declarationKeywords[strings.TrimSpace(prev)] ||
+				(lineStart && (next == "=" || next == "<-" || next == ":=")) {
+				found[name] = true
+			}
+		}
+	}
+
+	symbols := make([]string, 0, len(found))
+	for name := range found {
+		symbols = append(symbols, name)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// expandFieldAliases - expands sym: to the Symbols field
+func expandFieldAliases(term string) string {
+	return symbolField.ReplaceAllString(term, "${1}${2}Symbols:")
+}
+
+// symbolQuery - matches plain search terms against symbols
+// so that gists declaring a term rank above gists using it.
+// Returns nil when there are no plain terms.
+func symbolQuery(term string) query.Query {
+	var queries []query.Query
+	for _, word := range strings.Fields(term) {
+		if strings.HasPrefix(word, "-") || strings.Contains(word, ":") {
+			continue
+		}
+		q := query.NewMatchQuery(strings.Trim(word, "+\"'"))
+		q.SetField("Symbols")
+		q.SetBoost(symbolBoost)
+		queries = append(queries, q)
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+	return query.NewDisjunctionQuery(queries)
+}
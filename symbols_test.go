@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractSymbols(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		want     []string
+	}{
+		{"parse.py", "import pysam\n\nclass BamReader:\n    pass\n\ndef parse_bam(path):\n    min_quality = 20\n    return pysam.AlignmentFile(path)\n",
+			[]string{"BamReader", "min_quality", "parse_bam"}},
+		{"user.js", "function getUserName(user) {\n  const fullName = user.name;\n  return fullName;\n}\nlet count = 0;\n",
+			[]string{"count", "fullName", "getUserName"}},
+		{"main.go", "package main\n\ntype Server struct{}\n\nfunc (s *Server) Start() {}\n\nfunc main() {\n\tport := 8080\n\t_ = port\n}\n",
+			[]string{"Server", "Start", "main", "port"}},
+		{"env.sh", "#!/bin/bash\nBUCKET=s3://data\naws s3 cp $1 $BUCKET\n",
+			[]string{"BUCKET"}},
+		{"notes.txt", "just some words here\n", []string{}},
+	}
+	for _, tt := range tests {
+		if got := extractSymbols(tt.filename, tt.content); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("extractSymbols(%q) = %q; want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestExpandFieldAliases(t *testing.T) {
+	tests := []struct {
+		term string
+		want string
+	}{
+		{"sym:parse_bam", "Symbols:parse_bam"},
+		{"bam +sym:parse_bam -sym:test", "bam +Symbols:parse_bam -Symbols:test"},
+		{"nosym:x", "nosym:x"},
+	}
+	for _, tt := range tests {
+		if got := expandFieldAliases(tt.term); got != tt.want {
+			t.Errorf("expandFieldAliases(%q) = %q; want %q", tt.term, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blevesearch/bleve/search"
+	"github.com/google/go-github/github"
+)
+
+// tagPaths - returns tags along with their parents so that
+// filtering by #aws matches #aws/s3
+func tagPaths(tags []string) []string {
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, tag := range tags {
+		parts := strings.Split(strings.ToLower(tag), "/")
+		for i := range parts {
+			path := strings.Join(parts[:i+1], "/")
+			if seen[path] == false {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+type tagNode struct {
+	name     string
+	count    string
+	children map[string]*tagNode
+}
+
+// tagTree - arranges tag paths (aws, aws/s3) and their counts
+// as rows of a tree
+func tagTree(data [][]string) [][]string {
+	root := &tagNode{children: map[string]*tagNode{}}
+	for _, row := range data {
+		node := root
+		for _, part := range strings.Split(row[0], "/") {
+			child, ok := node.children[part]
+			if !ok {
+				child = &tagNode{name: part, children: map[string]*tagNode{}}
+				node.children[part] = child
+			}
+			node = child
+		}
+		node.count = row[1]
+	}
+
+	var rows [][]string
+	var walk func(node *tagNode, prefix string)
+	walk = func(node *tagNode, prefix string) {
+		names := make([]string, 0, len(node.children))
+		for name := range node.children {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			child := node.children[name]
+			branch, indent := "├─ ", "│  "
+			if i == len(names)-1 {
+				branch, indent = "└─ ", "   "
+			}
+			if node == root {
+				branch, indent = "", ""
+			}
+			rows = append(rows, []string{prefix + branch + child.name, child.count})
+			walk(child, prefix+indent)
+		}
+	}
+	walk(root, "")
+	return rows
+}
+
+func tagTreeTable(data [][]string) {
+	renderTable([]string{"Tags", "Count"}, tagTree(data))
+}
+
+// rewriteTags - replaces each tag in a description with the result
+// of replace. An empty result removes the tag. A tag that repeats
+// a renamed tag is removed; other repeated tags are kept.
+func rewriteTags(description string, replace func(tag string) string) string {
+	var result string
+	seen := map[string]bool{}
+	renamed := map[string]bool{}
+	curr := 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(description, -1) {
+		original := description[m[2]:m[3]]
+		tag := replace(original)
+		key := strings.ToLower(tag)
+		if tag != original {
+			renamed[key] = true
+		}
+		result += description[curr:m[0]]
+		if tag != "" && (seen[key] && renamed[key]) == false {
+			result += "#" + tag
+			seen[key] = true
+		} else {
+			result = strings.TrimRight(result, " ")
+		}
+		curr = m[1]
+	}
+	result += description[curr:]
+	return strings.TrimSpace(result)
+}
+
+func hasTag(description string, tag string) bool {
+	for _, t := range parseTags(description) {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// renameTag - renames old to new, including children (old/child)
+func renameTag(description string, old string, new string) string {
+	return rewriteTags(description, func(tag string) string {
+		if strings.EqualFold(tag, old) {
+			return new
+		} else if strings.HasPrefix(strings.ToLower(tag), strings.ToLower(old)+"/") {
+			return new + tag[len(old):]
+		}
+		return tag
+	})
+}
+
+// descriptionChange - a new description for a gist
+type descriptionChange struct {
+	gist        *search.DocumentMatch
+	description string
+}
+
+// tagChanges - applies update to the descriptions of gists owned by
+// the user, and returns the descriptions that change
+func tagChanges(gists []*search.DocumentMatch, update func(description string) string) []descriptionChange {
+	config, err := getConfig()
+	if err != nil {
+		ThrowError("Login to edit tags", 1)
+	}
+	var changes []descriptionChange
+	for _, gist := range gists {
+		description := gist.Fields["Description"].(string)
+		updated := update(description)
+		if updated == description {
+			continue
+		}
+		if gist.Fields["Owner"].(string) != config.Login {
+			errorMsg(fmt.Sprintf("Skipping %v; owned by %s\n", int(gist.Fields["IDX"].(float64)), gist.Fields["Owner"]))
+			continue
+		}
+		changes = append(changes, descriptionChange{gist, updated})
+	}
+	return changes
+}
+
+// taggedGists - returns gists carrying tag or its children
+func taggedGists(tag string) []*search.DocumentMatch {
+	return termGists("TagPaths", strings.ToLower(strings.TrimPrefix(tag, "#")))
+}
+
+func descriptionChangeTable(changes []descriptionChange) {
+	data := make([][]string, len(changes))
+	for i, change := range changes {
+		data[i] = []string{
+			fmt.Sprintf("%v", int(change.gist.Fields["IDX"].(float64))),
+			change.gist.Fields["Description"].(string),
+			greenText.Sprint(change.description),
+		}
+	}
+	renderTable([]string{"ID", "Description", "New Description"}, data)
+}
+
+// applyDescriptionChanges - previews changes and, once confirmed,
+// updates the gists on GitHub and the index in a single batch
+func applyDescriptionChanges(changes []descriptionChange, dryRun bool, yes bool) {
+	if len(changes) == 0 {
+		successMsg("No gists to update\n")
+		return
+	}
+	descriptionChangeTable(changes)
+	if dryRun {
+		return
+	}
+	if yes == false && confirmPrompt(fmt.Sprintf("Update %v gists?", len(changes))) == false {
+		return
+	}
+
+	client, _ := authenticate("")
+	batch := dbIdx.NewBatch()
+	updated := 0
+	for _, change := range changes {
+		gistID := change.gist.Fields["GistID"].(string)
+		resultGist, _, err := client.Gists.Edit(ctx, gistID, &github.Gist{Description: &change.description})
+		if err != nil {
+			errorMsg(fmt.Sprintf("Error updating %v: %s\n", int(change.gist.Fields["IDX"].(float64)), err))
+			continue
+		}
+		// Replace the record, retaining the same 'IDX' as before.
+		sn := snippetFromDoc(change.gist)
+		sn.ID = getGistRecID(resultGist)
+		sn.Description = change.description
+		sn.UpdatedAt = resultGist.GetUpdatedAt()
+		sn = refreshSnippet(sn)
+		if sn.ID != change.gist.ID {
+			batch.Delete(change.gist.ID)
+		}
+		batch.Index(sn.ID, sn)
+		updated++
+	}
+	if err := dbIdx.Batch(batch); err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+	successMsg(fmt.Sprintf("Updated %v gists\n", updated))
+}
+
+// idxGists - looks up gists from a list of IDs
+func idxGists(ids []string) []*search.DocumentMatch {
+	var gists []*search.DocumentMatch
+	for _, id := range ids {
+		v, err := strconv.Atoi(id)
+		if err != nil {
+			ThrowError(fmt.Sprintf("%v is an invalid ID", id), 1)
+		}
+		gists = append(gists, lookupGist(v))
+	}
+	return gists
+}
+
+// tagArgs - splits IDs and the trailing tag
+func tagArgs(args []string) ([]string, string) {
+	if len(args) < 2 {
+		ThrowError("Provide one or more gist IDs and a tag", 1)
+	}
+	return args[:len(args)-1], validTag(args[len(args)-1])
+}
+
+// validTag - checks that a tag (with or without #) can be parsed
+func validTag(tag string) string {
+	tag = strings.TrimPrefix(tag, "#")
+	if tags := parseTags("#" + tag); len(tags) != 1 || tags[0] != tag {
+		ThrowError(fmt.Sprintf("'%s' is not a valid tag; Use letters, numbers, _, - and /", tag), 1)
+	}
+	return tag
+}
+
+func addTags(args []string, dryRun bool, yes bool) {
+	ids, tag := tagArgs(args)
+	changes := tagChanges(idxGists(ids), func(description string) string {
+		if hasTag(description, tag) {
+			return description
+		}
+		return strings.TrimSpace(description + " #" + tag)
+	})
+	applyDescriptionChanges(changes, dryRun, yes)
+}
+
+func rmTags(args []string, dryRun bool, yes bool) {
+	ids, tag := tagArgs(args)
+	changes := tagChanges(idxGists(ids), func(description string) string {
+		return rewriteTags(description, func(t string) string {
+			return ifelse(strings.EqualFold(t, tag), "", t)
+		})
+	})
+	applyDescriptionChanges(changes, dryRun, yes)
+}
+
+// mergeTags - renames each of the tags to the last tag
+// across all gists; rename is a merge of a single tag
+func mergeTags(args []string, dryRun bool, yes bool) {
+	if len(args) < 2 {
+		ThrowError("Provide the tags to merge and the new tag", 1)
+	}
+	target := validTag(args[len(args)-1])
+	var gists []*search.DocumentMatch
+	seen := map[string]bool{}
+	var sources []string
+	for _, arg := range args[:len(args)-1] {
+		source := validTag(arg)
+		sources = append(sources, source)
+		for _, gist := range taggedGists(source) {
+			if seen[gist.ID] == false {
+				seen[gist.ID] = true
+				gists = append(gists, gist)
+			}
+		}
+	}
+	changes := tagChanges(gists, func(description string) string {
+		for _, source := range sources {
+			description = renameTag(description, source, target)
+		}
+		return description
+	})
+	applyDescriptionChanges(changes, dryRun, yes)
+}
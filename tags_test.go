@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenameTag(t *testing.T) {
+	tests := []struct {
+		description string
+		old         string
+		new         string
+		want        string
+	}{
+		{"Upload to s3 #aws", "aws", "cloud", "Upload to s3 #cloud"},
+		{"Upload to s3 #AWS", "aws", "cloud", "Upload to s3 #cloud"},
+		{"Read bam #genomics/bam", "genomics", "bio", "Read bam #bio/bam"},
+		{"Read bam #genomics-tools", "genomics", "bio", "Read bam #genomics-tools"},
+		// Merging into a tag that is already present
+		{"Upload #aws #s3", "s3", "aws", "Upload #aws"},
+		{"Upload #s3 #aws", "s3", "aws", "Upload #aws"},
+		// Repeated tags that are not renamed are kept
+		{"Upload #aws #aws #x", "x", "y", "Upload #aws #aws #y"},
+		{"Upload #aws #aws", "x", "y", "Upload #aws #aws"},
+	}
+	for _, tt := range tests {
+		if got := renameTag(tt.description, tt.old, tt.new); got != tt.want {
+			t.Errorf("renameTag(%q, %q, %q) = %q; want %q", tt.description, tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestRewriteTags(t *testing.T) {
+	remove := func(name string) func(string) string {
+		return func(tag string) string {
+			return ifelse(tag == name, "", tag)
+		}
+	}
+	tests := []struct {
+		description string
+		replace     func(string) string
+		want        string
+	}{
+		{"Upload to s3 #aws #x", remove("aws"), "Upload to s3 #x"},
+		{"Upload to s3 #aws #x", remove("x"), "Upload to s3 #aws"},
+		{"#aws upload", remove("aws"), "upload"},
+		{"Upload #aws #aws", remove("x"), "Upload #aws #aws"},
+		{"No tags", remove("aws"), "No tags"},
+	}
+	for _, tt := range tests {
+		if got := rewriteTags(tt.description, tt.replace); got != tt.want {
+			t.Errorf("rewriteTags(%q) = %q; want %q", tt.description, got, tt.want)
+		}
+	}
+}
+
+func TestTagPaths(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{[]string{"aws"}, []string{"aws"}},
+		{[]string{"aws/s3"}, []string{"aws", "aws/s3"}},
+		{[]string{"AWS/S3", "aws/ec2"}, []string{"aws", "aws/s3", "aws/ec2"}},
+		{[]string{"lang/go/cli", "genomics"}, []string{"lang", "lang/go", "lang/go/cli", "genomics"}},
+		{nil, []string{}},
+	}
+	for _, tt := range tests {
+		if got := tagPaths(tt.tags); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tagPaths(%q) = %q; want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestTagTree(t *testing.T) {
+	data := [][]string{
+		{"aws", "3"},
+		{"genomics", "1"},
+		{"aws/s3", "2"},
+		{"aws/ec2", "1"},
+		{"aws/s3/upload", "1"},
+	}
+	want := [][]string{
+		{"aws", "3"},
+		{"├─ ec2", "1"},
+		{"└─ s3", "2"},
+		{"   └─ upload", "1"},
+		{"genomics", "1"},
+	}
+	if got := tagTree(data); !reflect.DeepEqual(got, want) {
+		t.Errorf("tagTree() = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Template for --template; items are gistRecords, or
+// facetCounts when fields are summarized
+var outputTemplate *template.Template
+
+// Colors available to templates
+var templateColors = map[string]*color.Color{
+	"red":       color.New(color.FgRed),
+	"green":     color.New(color.FgGreen),
+	"yellow":    color.New(color.FgYellow),
+	"blue":      color.New(color.FgBlue),
+	"magenta":   color.New(color.FgMagenta),
+	"cyan":      color.New(color.FgCyan),
+	"white":     color.New(color.FgWhite),
+	"bold":      color.New(color.Bold),
+	"underline": color.New(color.Underline),
+}
+
+var templateFuncs = template.FuncMap{
+	// join .Tags ","
+	"join": func(list []string, sep string) string {
+		return strings.Join(list, sep)
+	},
+	// truncate 30 .Description
+	"truncate": func(n int, s string) string {
+		if runes := []rune(s); len(runes) > n {
+			if n <= 1 {
+				return string(runes[:n])
+			}
+			return string(runes[:n-1]) + "…"
+		}
+		return s
+	},
+	// pad 30 .Description
+	"pad": func(n int, s string) string {
+		if len([]rune(s)) >= n {
+			return s
+		}
+		return s + strings.Repeat(" ", n-len([]rune(s)))
+	},
+	// date .UpdatedAt
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02")
+	},
+	// datefmt "Jan 2, 2006" .UpdatedAt
+	"datefmt": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	// ago .UpdatedAt
+	"ago": timeAgo,
+	// color "green" .Description
+	"color": func(name string, v interface{}) (string, error) {
+		c, ok := templateColors[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("unknown color '%s'", name)
+		}
+		return c.Sprint(v), nil
+	},
+}
+
+// timeAgo - how long ago t was (e.g. 3 days ago)
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if n := int(d / unit.size); n >= 1 {
+			return fmt.Sprintf("%d %s%s ago", n, unit.name, ifelse(n == 1, "", "s"))
+		}
+	}
+	return "just now"
+}
+
+// setOutputTemplate - applies --template, which is either the name
+// of a template in the config or a template
+func setOutputTemplate(text string) {
+	if text == "" || outputFormat == "alfred" {
+		return
+	}
+	if outputFormat != "console" {
+		ThrowError("Use either --format or --template", 1)
+	}
+	config, _ := getConfig()
+	if named, ok := config.Templates[text]; ok {
+		text = named
+	}
+	t, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		ThrowError(fmt.Sprintf("Invalid template: %s", err), 1)
+	}
+	outputTemplate = t
+	outputFormat = "template"
+}
+
+// writeTemplate - outputs each record with the template;
+// a newline is added unless the template ends with one
+func writeTemplate(records []gistRecord) {
+	for _, record := range records {
+		writeTemplateItem(record)
+	}
+}
+
+// writeTemplateItem - prints one item with the template,
+// followed by a newline
+func writeTemplateItem(item interface{}) {
+	var out strings.Builder
+	if err := outputTemplate.Execute(&out, item); err != nil {
+		ThrowError(fmt.Sprintf("Template error: %s", err), 1)
+	}
+	fmt.Fprint(os.Stdout, out.String())
+	if strings.HasSuffix(out.String(), "\n") == false {
+		fmt.Println()
+	}
+}
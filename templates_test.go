@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	color.NoColor = true
+	updated := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	record := gistRecord{
+		IDX:         5,
+		Owner:       "dan",
+		Description: "Read bam files with pysam",
+		Tags:        []string{"genomics", "aws"},
+		UpdatedAt:   updated,
+	}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{.IDX}} {{.Description}}`, "5 Read bam files with pysam"},
+		{`{{join .Tags ","}}`, "genomics,aws"},
+		{`{{truncate 8 .Description}}`, "Read ba…"},
+		{`{{truncate 50 .Description}}`, "Read bam files with pysam"},
+		{`[{{pad 5 .Owner}}]`, "[dan  ]"},
+		{`[{{pad 2 .Owner}}]`, "[dan]"},
+		{`{{date .UpdatedAt}}`, "2020-03-01"},
+		{`{{datefmt "Jan 2, 2006" .UpdatedAt}}`, "Mar 1, 2020"},
+		{`{{color "green" .Owner}}`, "dan"},
+	}
+	for _, tt := range tests {
+		tmpl := template.Must(template.New("test").Funcs(templateFuncs).Parse(tt.text))
+		var out strings.Builder
+		if err := tmpl.Execute(&out, record); err != nil {
+			t.Errorf("%s: %s", tt.text, err)
+			continue
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s = %q; want %q", tt.text, out.String(), tt.want)
+		}
+	}
+
+	// Unknown colors are an error
+	tmpl := template.Must(template.New("test").Funcs(templateFuncs).Parse(`{{color "pink" .Owner}}`))
+	if err := tmpl.Execute(&strings.Builder{}, record); err == nil {
+		t.Error("expected an error for an unknown color")
+	}
+}
+
+func TestTimeAgo(t *testing.T) {
+	tests := []struct {
+		since time.Duration
+		want  string
+	}{
+		{10 * time.Second, "just now"},
+		{90 * time.Second, "1 minute ago"},
+		{3 * time.Hour, "3 hours ago"},
+		{24 * time.Hour, "1 day ago"},
+		{15 * 24 * time.Hour, "2 weeks ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+	}
+	for _, tt := range tests {
+		if got := timeAgo(time.Now().Add(-tt.since)); got != tt.want {
+			t.Errorf("timeAgo(-%v) = %q; want %q", tt.since, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/query"
+)
+
+// Local usage is keyed by GistID so that it persists across syncs.
+// It is never sent to GitHub.
+var libUsagePath = fmt.Sprintf("%s/usage.json", getLibraryDirectory())
+
+// Events recorded when gists are used
+var usageEvents = []string{"open", "copy", "run"}
+
+// The weight of a use halves every frecencyHalfLife
+const frecencyHalfLife = 14 * 24 * time.Hour
+
+// Frecency is stored as the log of the decayed number of uses
+// relative to a fixed time. Every score decays at the same rate,
+// so stored scores can be compared without updating them.
+var frecencyEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type usageRecord struct {
+	LastOpened time.Time      `json:"LastOpened"`
+	Events     map[string]int `json:"Events,omitempty"`
+	Frecency   float64        `json:"Frecency,omitempty"`
+}
+
+func loadUsage() map[string]usageRecord {
+	usage := map[string]usageRecord{}
+	data, err := ioutil.ReadFile(libUsagePath)
+	if err != nil {
+		return usage
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		ThrowError(fmt.Sprintf("Error reading %s", libUsagePath), 1)
+	}
+	return usage
+}
+
+func writeUsage(usage map[string]usageRecord) {
+	_ = os.Mkdir(getLibraryDirectory(), 0755)
+	out, err := json.Marshal(usage)
+	check(err)
+	err = ioutil.WriteFile(libUsagePath, out, 0644)
+	check(err)
+}
+
+// addFrecency - adds a use at time t to a frecency score;
+// a score of 0 means the gist has not been used.
+func addFrecency(score float64, t time.Time) float64 {
+	use := math.Ln2 * float64(t.Sub(frecencyEpoch)) / float64(frecencyHalfLife)
+	if score == 0 {
+		return use
+	}
+	// log(e^score + e^use)
+	hi, lo := math.Max(score, use), math.Min(score, use)
+	return hi + math.Log1p(math.Exp(lo-hi))
+}
+
+// recordUsage - records that a gist was opened, copied, or run
+// and updates its index record
+func recordUsage(gist *search.DocumentMatch, event string) {
+	if contains(usageEvents, event) == false {
+		ThrowError(fmt.Sprintf("Unknown event '%s'; Use one of: open, copy, run", event), 1)
+	}
+	gistID := fmt.Sprintf("%v", gist.Fields["GistID"])
+	usage := loadUsage()
+	record := usage[gistID]
+	now := time.Now()
+	if record.Events == nil {
+		record.Events = map[string]int{}
+	}
+	record.Events[event]++
+	record.LastOpened = now
+	record.Frecency = addFrecency(record.Frecency, now)
+	usage[gistID] = record
+	writeUsage(usage)
+
+	sn := snippetFromDoc(gist)
+	sn.LastOpened = record.LastOpened
+	sn.Frecency = record.Frecency
+	err := dbIdx.Index(sn.ID, sn)
+	check(err)
+}
+
+// clearUsage - removes recorded usage locally and from the index
+func clearUsage() {
+	os.Remove(libUsagePath)
+	results := dumpDb()
+	if results == nil {
+		ThrowError("Error reading library", 1)
+	}
+	batch := dbIdx.NewBatch()
+	for _, gist := range results.Hits {
+		if fieldFloat(gist, "Frecency") == 0 {
+			continue
+		}
+		sn := snippetFromDoc(gist)
+		sn.LastOpened = time.Time{}
+		sn.Frecency = 0
+		batch.Index(sn.ID, sn)
+	}
+	if err := dbIdx.Batch(batch); err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+	successMsg("Cleared usage\n")
+}
+
+// recent - lists the gists used most recently
+func recent(search *searchQuery) {
+	if search.sort == "" {
+		search.sort = "-last-opened"
+	}
+	// Unused gists have a frecency of 0
+	min, inclusive := 0.0, false
+	used := query.NewNumericRangeInclusiveQuery(&min, nil, &inclusive, nil)
+	used.SetField("Frecency")
+
+	sr := bleve.NewSearchRequest(andQuery(used, filterQueries(search)))
+	sr.Size = search.limit
+	sr.From = pageFrom(search)
+	sr.Fields = []string{"*"}
+	sortResults(sr, search, false)
+	results, err := dbIdx.Search(sr)
+	if err == nil && isDataFormat() {
+		writeResults(results, search)
+		return
+	}
+	if err != nil || len(results.Hits) == 0 {
+		errorMsg("No Results\n")
+		os.Exit(0)
+	}
+	if outputFormat == "console" {
+		resultTable(results, false, search.offset)
+		saveLastResults(results, false, search.offset)
+	} else if outputFormat == "alfred" {
+		resultListAlfred(results)
+	}
+}
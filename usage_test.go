@@ -0,0 +1,35 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAddFrecency(t *testing.T) {
+	near := func(a float64, b float64) bool {
+		return math.Abs(a-b) < 1e-9
+	}
+	t0 := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(frecencyHalfLife)
+
+	once := addFrecency(0, t0)
+	twice := addFrecency(once, t0)
+
+	// Two uses count twice as much as one
+	if !near(twice-once, math.Ln2) {
+		t.Errorf("two uses = %v; want one use (%v) + ln 2", twice, once)
+	}
+	// Two uses are equal to one use a half-life later
+	if later := addFrecency(0, t1); !near(later, twice) {
+		t.Errorf("a use a half-life later = %v; want %v", later, twice)
+	}
+	// A recent use ranks above an older use
+	if recent := addFrecency(0, t0.Add(time.Hour)); recent <= once {
+		t.Errorf("a recent use (%v) should rank above an older use (%v)", recent, once)
+	}
+	// The order of uses does not matter
+	if a, b := addFrecency(addFrecency(0, t0), t1), addFrecency(addFrecency(0, t1), t0); !near(a, b) {
+		t.Errorf("uses in a different order = %v, %v", a, b)
+	}
+}
@@ -9,11 +9,11 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"os/exec"
 	"os/user"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -40,6 +40,16 @@ type configuration struct {
 	Login     string    `json:"login"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Editor    string    `json:"editor"`
+	TagRules  []tagRule `json:"tag_rules,omitempty"`
+	// Named templates for --template
+	Templates map[string]string `json:"templates,omitempty"`
+	// Columns of the result table and the order
+	// in which they are dropped on narrow terminals
+	Columns     []string `json:"columns,omitempty"`
+	DropColumns []string `json:"drop_columns,omitempty"`
+	// Syntax highlighting
+	Style     string `json:"style,omitempty"`
+	Formatter string `json:"formatter,omitempty"`
 }
 
 type gistSort []*github.Gist
@@ -70,13 +80,23 @@ type Snippet struct {
 	Files       map[github.GistFilename]github.GistFile `json:"Files"`
 	NFiles      int                                     `json:"NFiles"`
 	NLines      int                                     `json:"NLines"`
+	FileSize    []int                                   `json:"FileSize"`
+	Size        int                                     `json:"Size"`
 	Language    []string                                `json:"Language"`
 	Filename    []string                                `json:"Filename"`
 	Tags        []string                                `json:"Tags"`
+	RuleTags    []string                                `json:"RuleTags"`
+	TagPaths    []string                                `json:"TagPaths"`
+	Symbols     []string                                `json:"Symbols"`
+	Note        string                                  `json:"Note"`
+	Collections []string                                `json:"Collections"`
+	Pinned      string                                  `json:"Pinned"`
 	Comments    int                                     `json:"Comments"`
 	CreatedAt   time.Time                               `json:"CreatedAt"`
 	UpdatedAt   time.Time                               `json:"UpdatedAt"`
 	URL         string                                  `json:"URL"`
+	LastOpened  time.Time                               `json:"LastOpened"`
+	Frecency    float64                                 `json:"Frecency"`
 }
 
 // Generate list of IDs for gists
@@ -103,8 +123,13 @@ func deleteLibrary() {
 }
 
 func initializeLibrary(AuthToken string, rebuild bool) bool {
+	// Settings are kept when logging in again or rebuilding
+	previous, _ := getConfig()
 	if rebuild {
-		deleteLibrary()
+		// Notes, saved searches, and usage are kept
+		dbIdx.Close()
+		os.RemoveAll(libDb)
+		os.Remove(libPath)
 		// Reload index
 		dbIdx = *openDb()
 	}
@@ -115,12 +140,10 @@ func initializeLibrary(AuthToken string, rebuild bool) bool {
 		ThrowError(fmt.Sprintf("Error authenticating: %s", resp), 1)
 	}
 
-	var config = configuration{
-		AuthToken: string(AuthToken),
-		Login:     string(user.GetLogin()),
-		UpdatedAt: time.Now(),
-		Editor:    "",
-	}
+	config := previous
+	config.AuthToken = string(AuthToken)
+	config.Login = string(user.GetLogin())
+	config.UpdatedAt = time.Now()
 	saveConfig(config)
 	return true
 }
@@ -195,7 +218,7 @@ func newGist(fileSet map[string]string, description string, public bool) {
 		ThrowError(fmt.Sprintf("Error: %s", err), 1)
 	}
 	// Add record to database
-	gistDbRec := gistDbRecord(resultGist, nextIdx(), []string{})
+	gistDbRec := gistDbRecord(resultGist, nextIdx(), []string{}, loadLocalData())
 	dbIdx.Index(gistDbRec.ID, gistDbRec)
 	// Print URL on success
 	boldUnderline.Println(*resultGist.HTMLURL)
@@ -233,19 +256,8 @@ func runGistEdit(params Snippet) {
 		log.Fatal(err)
 	}
 
-	var cmd *exec.Cmd
-	config, _ := getConfig()
-	var editor = config.Editor
-	if editor == "subl" {
-		cmd = exec.Command("subl", "--wait", fmt.Sprintf("%s", tmpfile.Name()))
-	} else if editor == "nano" {
-		cmd = exec.Command("nano", "-t", tmpfile.Name())
-	} else if editor == "vim" {
-		cmd = exec.Command("vim", tmpfile.Name())
-	} else if editor == "micro" {
-		cmd = exec.Command("micro", tmpfile.Name())
-	}
-	cmd.Stdin = os.Stdout
+	cmd := editorCommand(tmpfile.Name())
+	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -301,19 +313,8 @@ func editGist(gistID int) {
 		log.Fatal(err)
 	}
 
-	var cmd *exec.Cmd
-	config, _ := getConfig()
-	var editor = config.Editor
-	if editor == "subl" {
-		cmd = exec.Command("subl", "--wait", fmt.Sprintf("%s", tmpfile.Name()))
-	} else if editor == "nano" {
-		cmd = exec.Command("nano", "-t", tmpfile.Name())
-	} else if editor == "vim" {
-		cmd = exec.Command("vim", tmpfile.Name())
-	} else if editor == "micro" {
-		cmd = exec.Command("micro", tmpfile.Name())
-	}
-	cmd.Stdin = os.Stdout
+	cmd := editorCommand(tmpfile.Name())
+	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -367,7 +368,7 @@ func editGist(gistID int) {
 	// Delete the old record, and insert the new record below.
 	// Retain the same 'IDX' as before.
 	batch := dbIdx.NewBatch()
-	editGistDbRec := gistDbRecord(resultGist, int(dbGist.Fields["IDX"].(float64)), starIds)
+	editGistDbRec := gistDbRecord(resultGist, int(dbGist.Fields["IDX"].(float64)), starIds, loadLocalData())
 	batch.Index(editGistDbRec.ID, editGistDbRec)
 	batch.Delete(dbGist.ID)
 	dbIdx.Batch(batch)
@@ -391,11 +392,67 @@ func rmGist(gistID int) {
 	successMsg(msg)
 }
 
+func starGist(gistID int, starred bool) {
+	client, login := authenticate("")
+
+	dbGist := lookupGist(gistID)
+	var err error
+	if starred {
+		_, err = client.Gists.Star(ctx, dbGist.Fields["GistID"].(string))
+	} else {
+		_, err = client.Gists.Unstar(ctx, dbGist.Fields["GistID"].(string))
+	}
+	if err != nil {
+		ThrowError("Error updating star", 1)
+	}
+
+	// Gists of other users are only synced while starred
+	if starred == false && dbGist.Fields["Owner"].(string) != login {
+		err = dbIdx.Delete(dbGist.ID)
+		check(err)
+		return
+	}
+
+	resultGist, _, err := client.Gists.Get(ctx, dbGist.Fields["GistID"].(string))
+	if err != nil {
+		ThrowError(fmt.Sprintf("Error: %s", err), 1)
+	}
+	var starIds []string
+	if starred {
+		starIds = []string{getGistRecID(resultGist)}
+	}
+
+	// Replace the record, retaining the same 'IDX' as before.
+	batch := dbIdx.NewBatch()
+	starGistDbRec := gistDbRecord(resultGist, int(dbGist.Fields["IDX"].(float64)), starIds, loadLocalData())
+	if starGistDbRec.ID != dbGist.ID {
+		batch.Delete(dbGist.ID)
+	}
+	batch.Index(starGistDbRec.ID, starGistDbRec)
+	dbIdx.Batch(batch)
+}
+
 func getGistRecID(gist *github.Gist) string {
 	return fmt.Sprintf("%v::%v", gist.GetID(), gist.GetUpdatedAt())
 }
 
-func gistDbRecord(gist *github.Gist, idx int, starIDs []string) Snippet {
+// localData - data stored locally rather than on GitHub that
+// is added to index records. It is loaded once per sync.
+type localData struct {
+	usage       map[string]usageRecord
+	collections localCollections
+	notes       map[string]string
+}
+
+func loadLocalData() localData {
+	return localData{
+		usage:       loadUsage(),
+		collections: loadCollections(),
+		notes:       loadNotes(),
+	}
+}
+
+func gistDbRecord(gist *github.Gist, idx int, starIDs []string, local localData) Snippet {
 	gistRecID := getGistRecID(gist)
 	ch := make(chan *string, 50)
 	items := make(map[github.GistFilename]github.GistFile)
@@ -403,10 +460,13 @@ func gistDbRecord(gist *github.Gist, idx int, starIDs []string) Snippet {
 	// if not, download files.
 	filenames := []string{}
 	languages := []string{}
+	fileSizes := []int{}
+	symbols := []string{}
 	nlines := 0
+	size := 0
 	// Check whether document exists
 	if _, err := dbIdx.Document(gistRecID); err == nil {
-		for k := range gist.Files {
+		for _, k := range sortedFilenames(gist.Files) {
 			var updated = gist.Files[k]
 			// If RawURL is nil, the gist was generated
 			// locally and does not need to be retrieved.
@@ -416,6 +476,8 @@ func gistDbRecord(gist *github.Gist, idx int, starIDs []string) Snippet {
 				updated.Content = <-ch
 			}
 			nlines += len(strings.Split(*updated.Content, "\n"))
+			size += len(*updated.Content)
+			fileSizes = append(fileSizes, len(*updated.Content))
 			items[k] = updated
 			if gist.Files[k].Filename != nil {
 				filenames = append(filenames, *gist.Files[k].Filename)
@@ -423,9 +485,11 @@ func gistDbRecord(gist *github.Gist, idx int, starIDs []string) Snippet {
 			if gist.Files[k].Language != nil {
 				languages = append(languages, *gist.Files[k].Language)
 			}
+			symbols = append(symbols, extractSymbols(string(k), *updated.Content)...)
 		}
 	}
 	tags := parseTags(gist.GetDescription())
+	usage := local.usage[gist.GetID()]
 	var sn = Snippet{
 		ID:          getGistRecID(gist),
 		GistID:      gist.GetID(),
@@ -439,13 +503,20 @@ func gistDbRecord(gist *github.Gist, idx int, starIDs []string) Snippet {
 		Starred:     trueFalse(contains(starIDs, gistRecID)),
 		NFiles:      len(items),
 		NLines:      nlines,
+		FileSize:    fileSizes,
+		Size:        size,
 		Tags:        tags,
+		Symbols:     symbols,
+		Note:        local.notes[gist.GetID()],
 		Comments:    gist.GetComments(),
 		CreatedAt:   gist.GetCreatedAt(),
 		UpdatedAt:   gist.GetUpdatedAt(),
 		URL:         gist.GetHTMLURL(),
+		LastOpened:  usage.LastOpened,
+		Frecency:    usage.Frecency,
 	}
-	return sn
+	sn = applyCollections(sn, local.collections)
+	return applyTagRules(sn)
 }
 
 func updateLibrary() {
@@ -540,13 +611,14 @@ func updateLibrary() {
 	currentGistIds := make([]string, len(allGists))
 	idStart := nextIdx()
 	offset := 0
+	local := loadLocalData()
 	for i, gist := range allGists {
 		// Store gist in db
 		var gistDbRec Snippet
 		if contains(existingGistIds, getGistRecID(gist)) == false {
 			// Calculate nextIdx so IDs are static unless
 			// a rebuild is performed.
-			gistDbRec = gistDbRecord(gist, idStart+offset, starIDs)
+			gistDbRec = gistDbRecord(gist, idStart+offset, starIDs, local)
 			offset++
 		}
 		currentGistIds[i] = getGistRecID(gist)
@@ -642,6 +714,139 @@ func parseGistFilesStruct(gist *search.DocumentMatch) map[github.GistFilename]gi
 	return result
 }
 
+// refreshSnippet - recomputes the fields derived from
+// the description and file contents
+func refreshSnippet(sn Snippet) Snippet {
+	sn.Tags = parseTags(sn.Description)
+	sn = applyTagRules(sn)
+	sn.Symbols = []string{}
+	sn.Filename = []string{}
+	sn.FileSize = []int{}
+	sn.Size = 0
+	// Filenames and sizes are in the same order
+	for _, filename := range sortedFilenames(sn.Files) {
+		content := *sn.Files[filename].Content
+		sn.Symbols = append(sn.Symbols, extractSymbols(string(filename), content)...)
+		sn.Filename = append(sn.Filename, string(filename))
+		sn.FileSize = append(sn.FileSize, len(content))
+		sn.Size += len(content)
+	}
+	return sn
+}
+
+// sortedFilenames - filenames of a gist in order, so that
+// per-file fields line up with Filename
+func sortedFilenames(files map[github.GistFilename]github.GistFile) []github.GistFilename {
+	filenames := make([]github.GistFilename, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Slice(filenames, func(i, j int) bool {
+		return filenames[i] < filenames[j]
+	})
+	return filenames
+}
+
+// snippetFromDoc - rebuilds a Snippet from its index record
+// so that it can be re-indexed with local changes.
+func snippetFromDoc(gist *search.DocumentMatch) Snippet {
+	files := make(map[github.GistFilename]github.GistFile)
+	for _, item := range parseGistFiles(gist) {
+		var content, fname = item["content"], item["filename"]
+		var language, ftype, rawURL = item["language"], item["type"], item["raw_url"]
+		// Large numbers are formatted with an exponent
+		fsize, _ := strconv.ParseFloat(item["size"], 64)
+		size := int(fsize)
+		files[github.GistFilename(fname)] = github.GistFile{
+			Content:  &content,
+			Filename: &fname,
+			Language: &language,
+			Type:     &ftype,
+			RawURL:   &rawURL,
+			Size:     &size,
+		}
+	}
+	var fileSizes []int
+	for _, size := range fieldFloats(gist, "FileSize") {
+		fileSizes = append(fileSizes, int(size))
+	}
+	createdAt, _ := time.Parse(time.RFC3339, fmt.Sprintf("%v", gist.Fields["CreatedAt"]))
+	updatedAt, _ := time.Parse(time.RFC3339, fmt.Sprintf("%v", gist.Fields["UpdatedAt"]))
+	lastOpened, _ := time.Parse(time.RFC3339, fmt.Sprintf("%v", gist.Fields["LastOpened"]))
+	return Snippet{
+		ID:          gist.ID,
+		GistID:      fmt.Sprintf("%v", gist.Fields["GistID"]),
+		IDX:         int(gist.Fields["IDX"].(float64)),
+		Owner:       fmt.Sprintf("%v", gist.Fields["Owner"]),
+		Description: fmt.Sprintf("%v", gist.Fields["Description"]),
+		Public:      fmt.Sprintf("%v", gist.Fields["Public"]),
+		Starred:     fmt.Sprintf("%v", gist.Fields["Starred"]),
+		Files:       files,
+		NFiles:      int(fieldFloat(gist, "NFiles")),
+		NLines:      int(fieldFloat(gist, "NLines")),
+		FileSize:    fileSizes,
+		Size:        int(fieldFloat(gist, "Size")),
+		Language:    fieldStrings(gist, "Language"),
+		Filename:    fieldStrings(gist, "Filename"),
+		Tags:        fieldStrings(gist, "Tags"),
+		RuleTags:    fieldStrings(gist, "RuleTags"),
+		TagPaths:    fieldStrings(gist, "TagPaths"),
+		Symbols:     fieldStrings(gist, "Symbols"),
+		Note:        fieldString(gist, "Note"),
+		Collections: fieldStrings(gist, "Collections"),
+		Pinned:      fieldString(gist, "Pinned"),
+		Comments:    int(fieldFloat(gist, "Comments")),
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		URL:         fmt.Sprintf("%v", gist.Fields["URL"]),
+		LastOpened:  lastOpened,
+		Frecency:    fieldFloat(gist, "Frecency"),
+	}
+}
+
+// fieldStrings - returns a stored field that may hold one or more values
+func fieldStrings(gist *search.DocumentMatch, field string) []string {
+	switch val := gist.Fields[field].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		result := make([]string, len(val))
+		for i, v := range val {
+			result[i] = fmt.Sprintf("%v", v)
+		}
+		return result
+	default:
+		return []string{fmt.Sprintf("%v", val)}
+	}
+}
+
+func fieldString(gist *search.DocumentMatch, field string) string {
+	val, _ := gist.Fields[field].(string)
+	return val
+}
+
+func fieldFloat(gist *search.DocumentMatch, field string) float64 {
+	n, _ := gist.Fields[field].(float64)
+	return n
+}
+
+// fieldFloats - returns a stored numeric field that may hold one or more values
+func fieldFloats(gist *search.DocumentMatch, field string) []float64 {
+	switch val := gist.Fields[field].(type) {
+	case float64:
+		return []float64{val}
+	case []interface{}:
+		result := make([]float64, 0, len(val))
+		for _, v := range val {
+			if n, ok := v.(float64); ok {
+				result = append(result, n)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
 func GistToText(gist *search.DocumentMatch) string {
 	// Concatenates gist files into a single line of text
 	// for coying
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestRefreshSnippetFileOrder(t *testing.T) {
+	contents := map[string]string{
+		"b.py":   "print(1)\n",
+		"a.sh":   "echo hello world\n",
+		"c.json": "{}",
+	}
+	files := map[github.GistFilename]github.GistFile{}
+	for name, content := range contents {
+		content := content
+		files[github.GistFilename(name)] = github.GistFile{Content: &content}
+	}
+	// Map order is random, so the result is checked several times
+	for i := 0; i < 10; i++ {
+		sn := refreshSnippet(Snippet{Files: files})
+		if want := []string{"a.sh", "b.py", "c.json"}; !reflect.DeepEqual(sn.Filename, want) {
+			t.Fatalf("Filename = %q; want %q", sn.Filename, want)
+		}
+		if want := []int{17, 9, 2}; !reflect.DeepEqual(sn.FileSize, want) {
+			t.Fatalf("FileSize = %v; want %v", sn.FileSize, want)
+		}
+		if sn.Size != 28 {
+			t.Fatalf("Size = %v; want 28", sn.Size)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
 	"regexp"
 	"strings"
 )
@@ -36,6 +37,16 @@ func contains(arr []string, str string) bool {
 	return false
 }
 
+// indexOf - index of str in arr or -1
+func indexOf(arr []string, str string) int {
+	for idx, a := range arr {
+		if a == str {
+			return idx
+		}
+	}
+	return -1
+}
+
 func counter(arr []string) map[string]int {
 	var count = make(map[string]int)
 	for _, x := range arr {
@@ -83,10 +94,20 @@ func ifelse(s bool, t string, f string) string {
 	return f
 }
 
+func ifInt(s bool, t int, f int) int {
+	if s {
+		return t
+	}
+	return f
+}
+
+// Tags may contain letters, numbers, _, - and /
+// for hierarchy (e.g. #ci-cd #aws/s3)
+var tagPattern = regexp.MustCompile(`#([\p{L}\p{N}_]+(?:[-/][\p{L}\p{N}_]+)*)`)
+
 func parseTags(s string) []string {
 	// Extract tags from string field
-	re := regexp.MustCompile(`#([A-Za-z0-9]+)`)
-	r := re.FindAllStringSubmatch(s, -1)
+	r := tagPattern.FindAllStringSubmatch(s, -1)
 	var tagSet []string
 	if len(r) > 0 {
 		for _, tags := range r {
@@ -154,3 +175,24 @@ func truncateString(str string, num int) string {
 	}
 	return bnoden
 }
+
+// editorCommand - opens path in the editor set with 'gg set-editor',
+// falling back to $EDITOR
+func editorCommand(path string) *exec.Cmd {
+	config, _ := getConfig()
+	editor := config.Editor
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	switch editor {
+	case "":
+		return exec.Command("vim", path)
+	case "subl":
+		return exec.Command("subl", "--wait", path)
+	case "nano":
+		return exec.Command("nano", "-t", path)
+	}
+	// $EDITOR may include arguments
+	args := append(strings.Fields(editor), path)
+	return exec.Command(args[0], args[1:]...)
+}